@@ -0,0 +1,17 @@
+// Package buildinfo menyimpan informasi build yang diisi saat compile, contoh:
+//
+//	go build -ldflags "-X learn_project/buildinfo.Version=v1.2.0 \
+//	  -X learn_project/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X learn_project/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import "time"
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// StartTime adalah waktu proses mulai berjalan
+var StartTime = time.Now()
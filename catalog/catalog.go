@@ -0,0 +1,96 @@
+// Package catalog membaca dan menulis file katalog produk (CSV atau NDJSON) untuk
+// import dan export. Kolom file:
+//
+//	sku, name, description, price, categories, low_stock_threshold, stock, reserved
+//
+// categories berisi slug kategori, dipisah "|" di CSV atau array di NDJSON.
+// stock dan reserved hanya informasi di export: import mengabaikannya karena stok
+// hanya berubah lewat movement. Kolom lain yang tidak dikenal juga diabaikan, jadi
+// file hasil export bisa langsung di-import kembali.
+package catalog
+
+import (
+	"mime"
+	"path"
+	"strings"
+)
+
+// Format adalah format file katalog
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+// Columns adalah kolom file, sesuai urutan export CSV
+var Columns = []string{"sku", "name", "description", "price", "categories", "low_stock_threshold", "stock", "reserved"}
+
+// ContentType adalah MIME type format untuk response export
+func (f Format) ContentType() string {
+	if f == NDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Valid bernilai true untuk CSV dan NDJSON
+func (f Format) Valid() bool {
+	return f == CSV || f == NDJSON
+}
+
+// FormatOf menentukan format dari Content-Type atau nama file (ekstensi .csv, .ndjson, .jsonl).
+// Mengembalikan "" kalau tidak dikenali.
+func FormatOf(contentType, filename string) Format {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return CSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return NDJSON
+	}
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return CSV
+	case ".ndjson", ".jsonl":
+		return NDJSON
+	}
+	return ""
+}
+
+// Record adalah satu produk di file export
+type Record struct {
+	SKU               string   `json:"sku"`
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	Price             float64  `json:"price"`
+	Categories        []string `json:"categories"`
+	LowStockThreshold *int64   `json:"low_stock_threshold"`
+	Stock             int64    `json:"stock"`
+	Reserved          int64    `json:"reserved"`
+}
+
+// Row adalah satu baris file import. Field pointer nil berarti kolom tidak ada di
+// file sehingga nilai produk yang sudah ada tidak diubah.
+type Row struct {
+	// Line adalah nomor baris di file (untuk CSV termasuk header), dipakai di laporan error
+	Line        int
+	SKU         string
+	Name        *string
+	Description *string
+	Price       *float64
+	Categories  *[]string
+	// HasThreshold bernilai true kalau kolom low_stock_threshold ada; LowStockThreshold nil
+	// berarti threshold dihapus
+	HasThreshold      bool
+	LowStockThreshold *int64
+
+	// Err berisi nilai yang tidak bisa dibaca (mis. price bukan angka)
+	Err *FieldError
+}
+
+// FieldError adalah nilai kolom yang tidak valid di satu baris
+type FieldError struct {
+	Field  string
+	Reason string
+}
@@ -0,0 +1,111 @@
+package catalog_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"learn_project/catalog"
+)
+
+func readAll(t *testing.T, file string, format catalog.Format) []catalog.Row {
+	t.Helper()
+
+	var rows []catalog.Row
+	err := catalog.Read(strings.NewReader(file), format, func(row catalog.Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestRoundTrip(t *testing.T) {
+	threshold := int64(3)
+	records := []catalog.Record{
+		{SKU: "A-1", Name: "Kaos, \"polos\"", Description: "=HYPERLINK(\"x\")", Price: 99.5, Categories: []string{"baju", "pria"}, LowStockThreshold: &threshold, Stock: 10, Reserved: 2},
+		{SKU: "B-2", Name: "Topi", Price: 1000, Categories: []string{}},
+	}
+
+	for _, format := range []catalog.Format{catalog.CSV, catalog.NDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := catalog.NewWriter(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, record := range records {
+				if err := writer.Write(record); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if format == catalog.CSV && !strings.Contains(buf.String(), "'=HYPERLINK") {
+				t.Fatalf("formula tidak di-escape: %s", buf.String())
+			}
+
+			rows := readAll(t, buf.String(), format)
+			if len(rows) != 2 {
+				t.Fatalf("rows = %d", len(rows))
+			}
+			first, second := rows[0], rows[1]
+			if first.Err != nil || first.SKU != "A-1" || *first.Name != records[0].Name || *first.Description != records[0].Description || *first.Price != 99.5 {
+				t.Fatalf("row = %+v", first)
+			}
+			if got := *first.Categories; len(got) != 2 || got[1] != "pria" || !first.HasThreshold || *first.LowStockThreshold != 3 {
+				t.Fatalf("row = %+v", first)
+			}
+			if len(*second.Categories) != 0 || !second.HasThreshold || second.LowStockThreshold != nil {
+				t.Fatalf("row = %+v", second)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	// BOM dan delimiter ";" (export Excel locale Indonesia), kolom tanpa description
+	rows := readAll(t, "\ufeffSKU;Name;Price\nA-1;Kaos;abc\n;;\nB-2;Topi;1000\n", catalog.CSV)
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[0].Err == nil || rows[0].Err.Field != "price" || rows[0].Line != 2 {
+		t.Fatalf("row = %+v", rows[0])
+	}
+	if rows[1].Line != 4 || rows[1].Description != nil || rows[1].Categories != nil || rows[1].HasThreshold {
+		t.Fatalf("row = %+v", rows[1])
+	}
+
+	for name, file := range map[string]string{
+		"empty":     "",
+		"no sku":    "name,price\nKaos,1\n",
+		"bad quote": "sku,name\nA-1,\"Kaos\n",
+	} {
+		err := catalog.Read(strings.NewReader(file), catalog.CSV, func(catalog.Row) error { return nil })
+		var fileErr *catalog.FileError
+		if !errors.As(err, &fileErr) {
+			t.Errorf("%s: err = %v, want FileError", name, err)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	for _, tc := range []struct {
+		contentType, filename string
+		want                  catalog.Format
+	}{
+		{"text/csv; charset=utf-8", "", catalog.CSV},
+		{"application/x-ndjson", "", catalog.NDJSON},
+		{"application/octet-stream", "produk.CSV", catalog.CSV},
+		{"", "produk.jsonl", catalog.NDJSON},
+		{"application/pdf", "produk.pdf", ""},
+	} {
+		if got := catalog.FormatOf(tc.contentType, tc.filename); got != tc.want {
+			t.Errorf("FormatOf(%q, %q) = %q, want %q", tc.contentType, tc.filename, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package catalog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxLineSize adalah panjang maksimal satu baris NDJSON
+const maxLineSize = 1024 * 1024
+
+// FileError adalah kesalahan yang membuat seluruh file tidak bisa dibaca
+// (mis. header CSV tanpa kolom sku), berbeda dengan Row.Err yang per baris
+type FileError struct {
+	Line   int
+	Reason string
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Read membaca file katalog baris demi baris dan memanggil fn untuk setiap baris.
+// Baris kosong dilewati.
+func Read(r io.Reader, format Format, fn func(Row) error) error {
+	if format == NDJSON {
+		return readNDJSON(r, fn)
+	}
+	return readCSV(r, fn)
+}
+
+func readCSV(r io.Reader, fn func(Row) error) error {
+	buffered := bufio.NewReader(r)
+	// Excel menulis BOM UTF-8 di awal file
+	if bom, _ := buffered.Peek(3); bytes.Equal(bom, []byte("\xEF\xBB\xBF")) {
+		buffered.Discard(3)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	// Spreadsheet dengan locale Indonesia memakai ";" sebagai pemisah kolom
+	first, _ := buffered.Peek(4096)
+	if header, _, _ := bytes.Cut(first, []byte("\n")); bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &FileError{Line: 1, Reason: "file is empty"}
+	}
+	if err != nil {
+		return &FileError{Line: 1, Reason: err.Error()}
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["sku"]; !ok {
+		return &FileError{Line: 1, Reason: "header has no sku column"}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return &FileError{Line: parseErr.Line, Reason: parseErr.Err.Error()}
+			}
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		if !slices.ContainsFunc(record, func(value string) bool { return strings.TrimSpace(value) != "" }) {
+			continue
+		}
+
+		cell := func(name string) (string, bool) {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return "", false
+			}
+			return strings.TrimSpace(record[i]), true
+		}
+		if err := fn(csvRow(line, cell)); err != nil {
+			return err
+		}
+	}
+}
+
+func csvRow(line int, cell func(string) (string, bool)) Row {
+	row := Row{Line: line}
+	row.SKU, _ = cell("sku")
+	if value, ok := cell("name"); ok {
+		value = unescapeFormula(value)
+		row.Name = &value
+	}
+	if value, ok := cell("description"); ok {
+		value = unescapeFormula(value)
+		row.Description = &value
+	}
+	if value, ok := cell("price"); ok && value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			row.Err = &FieldError{Field: "price", Reason: "not a number"}
+			return row
+		}
+		row.Price = &price
+	}
+	if value, ok := cell("categories"); ok {
+		slugs := []string{}
+		for _, slug := range strings.Split(value, "|") {
+			if slug = strings.TrimSpace(slug); slug != "" {
+				slugs = append(slugs, slug)
+			}
+		}
+		row.Categories = &slugs
+	}
+	if value, ok := cell("low_stock_threshold"); ok {
+		row.HasThreshold = true
+		if value != "" {
+			threshold, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				row.Err = &FieldError{Field: "low_stock_threshold", Reason: "not an integer"}
+				return row
+			}
+			row.LowStockThreshold = &threshold
+		}
+	}
+	return row
+}
+
+func readNDJSON(r io.Reader, fn func(Row) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := fn(ndjsonRow(line, data)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &FileError{Line: 0, Reason: err.Error()}
+	}
+	return nil
+}
+
+func ndjsonRow(line int, data []byte) Row {
+	row := Row{Line: line}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		row.Err = &FieldError{Reason: "not a JSON object"}
+		return row
+	}
+
+	decode := func(name string, dest any) bool {
+		raw, ok := fields[name]
+		if !ok {
+			return false
+		}
+		if err := json.Unmarshal(raw, dest); err != nil && row.Err == nil {
+			row.Err = &FieldError{Field: name, Reason: "wrong type"}
+		}
+		return true
+	}
+
+	var sku string
+	decode("sku", &sku)
+	row.SKU = strings.TrimSpace(sku)
+	var name, description string
+	if decode("name", &name) {
+		name = strings.TrimSpace(name)
+		row.Name = &name
+	}
+	if decode("description", &description) {
+		row.Description = &description
+	}
+	var price float64
+	if decode("price", &price) {
+		row.Price = &price
+	}
+	var categories []string
+	if decode("categories", &categories) {
+		if categories == nil {
+			categories = []string{}
+		}
+		row.Categories = &categories
+	}
+	row.HasThreshold = decode("low_stock_threshold", &row.LowStockThreshold)
+	return row
+}
@@ -0,0 +1,82 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Writer menulis Record ke file export
+type Writer interface {
+	Write(Record) error
+	// Flush mengirim data yang masih di buffer ke writer di bawahnya
+	Flush() error
+}
+
+// NewWriter membuat Writer untuk format; CSV langsung menulis header
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	if format == NDJSON {
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	}
+	writer := &csvWriter{csv: csv.NewWriter(w)}
+	return writer, writer.csv.Write(Columns)
+}
+
+type csvWriter struct {
+	csv *csv.Writer
+}
+
+func (w *csvWriter) Write(record Record) error {
+	threshold := ""
+	if record.LowStockThreshold != nil {
+		threshold = strconv.FormatInt(*record.LowStockThreshold, 10)
+	}
+	return w.csv.Write([]string{
+		record.SKU,
+		escapeFormula(record.Name),
+		escapeFormula(record.Description),
+		strconv.FormatFloat(record.Price, 'f', -1, 64),
+		strings.Join(record.Categories, "|"),
+		threshold,
+		strconv.FormatInt(record.Stock, 10),
+		strconv.FormatInt(record.Reserved, 10),
+	})
+}
+
+func (w *csvWriter) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(record Record) error {
+	if record.Categories == nil {
+		record.Categories = []string{}
+	}
+	return w.encoder.Encode(record)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// escapeFormula mencegah CSV injection: teks yang diawali = + - @ dianggap formula
+// oleh spreadsheet, jadi diberi awalan ' (dibuang lagi oleh unescapeFormula saat import)
+func escapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
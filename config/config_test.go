@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testSecret        = "01234567890123456789012345678901"
+	testRefreshSecret = "abcdefghijabcdefghijabcdefghijab"
+)
+
+// envNames mengembalikan semua nama di tag `env` milik t dan struct di dalamnya
+func envNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+			names = append(names, envNames(field.Type)...)
+			continue
+		}
+		if name := field.Tag.Get("env"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// setup menjalankan test di direktori kosong dengan environment konfigurasi yang
+// bersih. Nilai dari .env (ditulis godotenv ke environment proses) ikut dikembalikan
+// oleh cleanup t.Setenv. Variabel env di vars diisi sesudahnya.
+func setup(t *testing.T, files map[string]string, vars map[string]string) {
+	t.Helper()
+
+	for _, name := range append(envNames(reflect.TypeOf(Config{})), "CONFIG_FILE") {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	for name, value := range vars {
+		t.Setenv(name, value)
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// requiredVars adalah nilai wajib yang tidak punya default
+func requiredVars(extra map[string]string) map[string]string {
+	vars := map[string]string{
+		"JWT_SECRET":         testSecret,
+		"JWT_SECRET_REFRESH": testRefreshSecret,
+		"DB_USER":            "app",
+		"DB_NAME":            "learn",
+	}
+	for name, value := range extra {
+		vars[name] = value
+	}
+	return vars
+}
+
+func TestDefaults(t *testing.T) {
+	setup(t, nil, requiredVars(nil))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := defaults()
+	want.JWT = JWTConfig{Secret: testSecret, RefreshSecret: testRefreshSecret}
+	want.Database.User, want.Database.Name = "app", "learn"
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v\nwant %+v", cfg, want)
+	}
+	if cfg.Server.Addr() != ":3000" || cfg.Admin.Addr() != "127.0.0.1:9090" {
+		t.Fatalf("addr = %s, admin %s", cfg.Server.Addr(), cfg.Admin.Addr())
+	}
+}
+
+// Urutan prioritas: default < file YAML < .env < environment variable
+func TestPrecedence(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		yaml, dotenv, env   string
+		wantPort            int
+		wantLevel, wantHost string
+	}{
+		{name: "default", wantPort: 3000, wantLevel: "info", wantHost: "localhost"},
+		{name: "yaml", yaml: "4000", wantPort: 4000, wantLevel: "debug", wantHost: "db.yaml"},
+		{name: "dotenv over yaml", yaml: "4000", dotenv: "5000", wantPort: 5000, wantLevel: "warn", wantHost: "db.dotenv"},
+		{name: "env over dotenv", yaml: "4000", dotenv: "5000", env: "6000", wantPort: 6000, wantLevel: "error", wantHost: "db.env"},
+		{name: "env over yaml", yaml: "4000", env: "6000", wantPort: 6000, wantLevel: "error", wantHost: "db.env"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{}
+			if tc.yaml != "" {
+				files["config.yaml"] = "server:\n  port: " + tc.yaml + "\nlog:\n  level: debug\ndatabase:\n  host: db.yaml\n"
+			}
+			if tc.dotenv != "" {
+				files[".env"] = "SERVER_PORT=" + tc.dotenv + "\nLOG_LEVEL=warn\nDB_HOST=db.dotenv\n"
+			}
+			vars := map[string]string{}
+			if tc.env != "" {
+				vars = map[string]string{"SERVER_PORT": tc.env, "LOG_LEVEL": "error", "DB_HOST": "db.env"}
+			}
+			setup(t, files, requiredVars(vars))
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Server.Port != tc.wantPort || cfg.Log.Level != tc.wantLevel || cfg.Database.Host != tc.wantHost {
+				t.Fatalf("port %d, level %s, host %s", cfg.Server.Port, cfg.Log.Level, cfg.Database.Host)
+			}
+		})
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	setup(t, map[string]string{"app.yaml": "server:\n  read_timeout: 3s\n"}, requiredVars(map[string]string{"CONFIG_FILE": "app.yaml"}))
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.ReadTimeout != 3*time.Second {
+		t.Fatalf("read timeout = %s", cfg.Server.ReadTimeout)
+	}
+
+	// CONFIG_FILE yang disebut eksplisit wajib ada
+	setup(t, nil, requiredVars(map[string]string{"CONFIG_FILE": "missing.yaml"}))
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// Validate melaporkan semua masalah sekaligus
+func TestValidateReportsAllProblems(t *testing.T) {
+	cfg := defaults()
+	cfg.JWT = JWTConfig{Secret: "pendek", RefreshSecret: testRefreshSecret}
+	cfg.Database.User, cfg.Database.Name = "app", "learn"
+	cfg.Database.Port = "lima"
+	cfg.Server.Port = 70000
+
+	var validationErr *ValidationError
+	if err := cfg.Validate(); !errors.As(err, &validationErr) {
+		t.Fatalf("err = %v", err)
+	}
+	for _, want := range []string{"JWT_SECRET minimal", `DB_PORT "lima" bukan angka`, "SERVER_PORT 70000"} {
+		if !containsProblem(validationErr.Problems, want) {
+			t.Errorf("problems %q tidak berisi %q", validationErr.Problems, want)
+		}
+	}
+	if len(validationErr.Problems) != 3 {
+		t.Errorf("problems = %q", validationErr.Problems)
+	}
+
+	// ValidateDatabase hanya melaporkan masalah database
+	if err := cfg.ValidateDatabase(); !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		vars  map[string]string
+		want  string
+	}{
+		{"duration without unit", nil, map[string]string{"SERVER_READ_TIMEOUT": "10"}, "SERVER_READ_TIMEOUT"},
+		{"bad duration", nil, map[string]string{"DB_CONNECT_TIMEOUT": "soon"}, "DB_CONNECT_TIMEOUT"},
+		{"bad int", nil, map[string]string{"SERVER_PORT": "3000x"}, "SERVER_PORT"},
+		{"bad int64", nil, map[string]string{"STORAGE_MAX_IMAGE_SIZE": "3MB"}, "STORAGE_MAX_IMAGE_SIZE"},
+		{"bad bool", nil, map[string]string{"ADMIN_ENABLED": "maybe"}, "ADMIN_ENABLED"},
+		{"bad float", nil, map[string]string{"TRACING_SAMPLE_RATIO": "half"}, "TRACING_SAMPLE_RATIO"},
+		{"bad int in .env", map[string]string{".env": "WEBHOOK_QUEUE_SIZE=many\n"}, nil, "WEBHOOK_QUEUE_SIZE"},
+		{"bad duration in yaml", map[string]string{"config.yaml": "server:\n  idle_timeout: forever\n"}, nil, "config.yaml"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setup(t, tc.files, requiredVars(tc.vars))
+
+			_, err := Load()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("err = %v, want mention of %s", err, tc.want)
+			}
+		})
+	}
+}
+
+func containsProblem(problems []string, want string) bool {
+	for _, problem := range problems {
+		if strings.Contains(problem, want) {
+			return true
+		}
+	}
+	return false
+}
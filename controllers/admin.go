@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PoolStats mengembalikan statistik connection pool database (untuk admin app)
+func PoolStats(db *sql.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		stats := db.Stats()
+		return c.JSON(fiber.Map{
+			"max_open_connections": stats.MaxOpenConnections,
+			"open_connections":     stats.OpenConnections,
+			"in_use":               stats.InUse,
+			"idle":                 stats.Idle,
+			"wait_count":           stats.WaitCount,
+			"wait_duration_ms":     stats.WaitDuration.Milliseconds(),
+			"max_idle_closed":      stats.MaxIdleClosed,
+			"max_idle_time_closed": stats.MaxIdleTimeClosed,
+			"max_lifetime_closed":  stats.MaxLifetimeClosed,
+		})
+	}
+}
@@ -2,8 +2,12 @@
 package controllers
 
 import (
-	"learn_project/database"
+	"errors"
+
+	"learn_project/logging"
+	"learn_project/metrics"
 	"learn_project/models"
+	"learn_project/repository"
 	"learn_project/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -21,72 +25,83 @@ type LoginInput struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
-func Register(c *fiber.Ctx) error {
+func (h *Handler) Register(c *fiber.Ctx) error {
 	var input RegisterInput
 	if err := c.BodyParser(&input); err != nil {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid input", nil)
+		return utils.ErrInvalidInput
 	}
 
 	if input.Name == "" || input.Email == "" || input.Password == "" {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "All fields are required", nil)
+		return utils.ErrMissingFields
 	}
 
-	var existingUser models.User
-	if err := database.DB.Where("email = ?", input.Email).First(&existingUser).Error; err == nil {
-		return utils.ResponseError(c, fiber.StatusConflict, "Email already in use", nil)
+	if _, err := h.Users.FindByEmail(c.UserContext(), input.Email); err == nil {
+		return utils.ErrEmailInUse
 	}
 
 	user := models.User{
 		Name:  input.Name,
 		Email: input.Email,
+		Role:  models.RoleUser,
 	}
 
-	if err := user.HashPassword(input.Password); err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not hash password", nil)
+	if err := user.HashPassword(c.UserContext(), input.Password); err != nil {
+		return utils.ErrPasswordHash.Wrap(err)
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not create user", nil)
+	if err := h.Users.Create(c.UserContext(), &user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return utils.ErrEmailInUse
+		}
+		return utils.ErrUserCreate.Wrap(err)
 	}
 
-	return utils.ResponseSuccessOneData(c, "User registered successfully", fiber.Map{
+	metrics.RegistrationsTotal.Inc()
+	logging.FromContext(c.UserContext()).Info("user registered", "user_id", user.ID)
+
+	return utils.ResponseSuccessOneData(c, utils.MsgUserRegistered, fiber.Map{
 		"id":    user.ID,
 		"name":  user.Name,
 		"email": user.Email,
 	})
 }
 
-func Login(c *fiber.Ctx) error {
+func (h *Handler) Login(c *fiber.Ctx) error {
 	var input LoginInput
 	if err := c.BodyParser(&input); err != nil {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid input", nil)
+		return utils.ErrInvalidInput
 	}
 
 	// Cari user berdasarkan email
-	var user models.User
-	if err := database.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusUnauthorized, "Invalid credentials", nil)
+	user, err := h.Users.FindByEmail(c.UserContext(), input.Email)
+	if err != nil {
+		metrics.LoginsTotal.WithLabelValues("failure").Inc()
+		return utils.ErrInvalidCredentials
 	}
 
 	// Periksa password
-	if err := user.CheckPassword(input.Password); err != nil {
-		return utils.ResponseError(c, fiber.StatusUnauthorized, "Invalid credentials", nil)
+	if err := user.CheckPassword(c.UserContext(), input.Password); err != nil {
+		metrics.LoginsTotal.WithLabelValues("failure").Inc()
+		logging.FromContext(c.UserContext()).Warn("login failed: wrong password", "user_id", user.ID)
+		return utils.ErrInvalidCredentials
 	}
 
 	// Generate JWT Token
-	accessToken, exp, err := utils.GenerateToken(user.Email)
+	accessToken, exp, err := h.JWT.GenerateToken(c.UserContext(), user.ID.String(), user.Email)
 	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not generate token", nil)
+		return utils.ErrTokenGeneration.Wrap(err)
 	}
 
 	// Generate Refresh Token
-	refreshToken, err := utils.GenerateRefreshToken(user.Email)
+	refreshToken, err := h.JWT.GenerateRefreshToken(c.UserContext(), user.ID.String(), user.Email)
 	if err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not generate refresh token", nil)
+		return utils.ErrRefreshToken.Wrap(err)
 	}
 
+	metrics.LoginsTotal.WithLabelValues("success").Inc()
+
 	// Return response dengan user data dan token
-	return utils.ResponseSuccessOneData(c, "Login successful", fiber.Map{
+	return utils.ResponseSuccessOneData(c, utils.MsgLoginSuccess, fiber.Map{
 		"user": fiber.Map{
 			"id":    user.ID,
 			"name":  user.Name,
@@ -98,20 +113,16 @@ func Login(c *fiber.Ctx) error {
 	})
 }
 
-func GetUser(c *fiber.Ctx) error {
-	email, ok := c.Locals("email").(string)
-	if !ok {
-		return utils.ResponseError(c, fiber.StatusUnauthorized, "Unauthorized", nil)
-	}
-
-	var user models.User
-	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusNotFound, "User not found", nil)
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
 	}
 
-	return utils.ResponseSuccessOneData(c, "User data retrieved successfully", fiber.Map{
+	return utils.ResponseSuccessOneData(c, utils.MsgUserRetrieved, fiber.Map{
 		"id":    user.ID,
 		"name":  user.Name,
 		"email": user.Email,
+		"role":  user.Role,
 	})
 }
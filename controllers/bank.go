@@ -1,10 +1,14 @@
 package controllers
 
 import (
-	"learn_project/database"
+	"errors"
+	"learn_project/logging"
+	"learn_project/metrics"
 	"learn_project/models"
+	"learn_project/patch"
+	"learn_project/repository"
 	"learn_project/utils"
-	"strconv"
+	"regexp"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -14,30 +18,47 @@ import (
 type BankInput struct {
 	BankName  string `json:"bank_name" validate:"required"`
 	AccountNo string `json:"account_no" validate:"required"`
+	Currency  string `json:"currency"` // Opsional saat create, default IDR
 }
 
-// Add a new bank account (CREATE)
-func AddBank(c *fiber.Ctx) error {
-	email, ok := c.Locals("email").(string)
-	if !ok {
-		return utils.ResponseError(c, fiber.StatusUnauthorized, "Unauthorized", nil)
-	}
+// BankDetailsInput adalah field bank yang bisa diubah: body PUT /bank/:id (penggantian
+// penuh, keduanya wajib) dan dokumen yang di-patch oleh PATCH. Currency tidak bisa
+// diubah karena saldo tercatat dalam mata uang itu.
+type BankDetailsInput struct {
+	BankName  string `json:"bank_name"`
+	AccountNo string `json:"account_no"`
+}
+
+// Kode mata uang ISO 4217: tiga huruf kapital
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
 
+// Add a new bank account (CREATE)
+func (h *Handler) AddBank(c *fiber.Ctx) error {
 	// Get user
-	var user models.User
-	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusNotFound, "User not found", nil)
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
 	}
 
 	var input BankInput
 	if err := c.BodyParser(&input); err != nil {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid input", nil)
+		return utils.ErrInvalidInput
+	}
+	if strings.TrimSpace(input.BankName) == "" || strings.TrimSpace(input.AccountNo) == "" {
+		return utils.ErrBankFieldsRequired
+	}
+
+	if input.Currency == "" {
+		input.Currency = "IDR"
+	}
+	input.Currency = strings.ToUpper(input.Currency)
+	if !currencyCode.MatchString(input.Currency) {
+		return utils.ErrInvalidCurrency
 	}
 
 	// Check if account number is unique
-	var existingBank models.Bank
-	if err := database.DB.Where("account_no = ?", input.AccountNo).First(&existingBank).Error; err == nil {
-		return utils.ResponseError(c, fiber.StatusConflict, "Account number already in use", nil)
+	if _, err := h.Banks.FindByAccountNo(c.UserContext(), input.AccountNo); err == nil {
+		return utils.ErrAccountNoInUse
 	}
 
 	bank := models.Bank{
@@ -45,96 +66,161 @@ func AddBank(c *fiber.Ctx) error {
 		BankName:  input.BankName,
 		AccountNo: input.AccountNo,
 		Nominal:   0, // Default balance 0
+		Currency:  input.Currency,
 	}
 
-	if err := database.DB.Create(&bank).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not add bank", nil)
+	if err := h.Banks.Create(c.UserContext(), &bank); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return utils.ErrAccountNoInUse
+		}
+		return utils.ErrBankCreate.Wrap(err)
 	}
 
-	return utils.ResponseSuccessOneData(c, "Bank added successfully", fiber.Map{
+	setETag(c, bank.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgBankAdded, fiber.Map{
 		"id":         bank.ID,
 		"bank_name":  bank.BankName,
 		"account_no": bank.AccountNo,
 		"nominal":    bank.Nominal,
+		"currency":   bank.Currency,
 	})
 }
 
 // Get all banks for a user (READ)
-func GetUserBanks(c *fiber.Ctx) error {
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	search := c.Query("search", "")
-	offset := (page - 1) * limit
+func (h *Handler) GetUserBanks(c *fiber.Ctx) error {
+	q, err := h.listQuery(c, repository.BankListSpec)
+	if err != nil {
+		return err
+	}
 
-	email, ok := c.Locals("email").(string)
-	if !ok {
-		return utils.ResponseError(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
 	}
 
-	var user models.User
-	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusNotFound, "User not found", nil)
+	page, err := h.Banks.ListByUser(c.UserContext(), user.ID, q)
+	if err != nil {
+		return utils.ErrBankList.Wrap(err)
 	}
 
-	var banks []models.Bank
-	query := database.DB.Where("user_id = ?", user.ID).Offset(offset).Limit(limit)
+	return utils.ResponseSuccessManyData(c, utils.MsgBanksRetrieved, page.Items, q.Page, q.Limit, int(page.Count), page.NextCursor)
+}
 
-	if search != "" {
-		search = strings.ToLower(search)
-		query = query.Where("LOWER(bank_name) LIKE ? OR account_no LIKE ?", "%"+search+"%", "%"+search+"%")
+// GetBank mengembalikan satu bank beserta ETag-nya (304 kalau If-None-Match masih cocok)
+func (h *Handler) GetBank(c *fiber.Ctx) error {
+	bank, err := h.findBank(c)
+	if err != nil {
+		return err
 	}
-
-	if err := query.Find(&banks).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not fetch banks", nil)
+	if notModified(c, bank.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
 
-	var count int64
-	countQuery := database.DB.Model(&models.Bank{}).Where("user_id = ?", user.ID)
+	return utils.ResponseSuccessOneData(c, utils.MsgBankRetrieved, bank)
+}
 
-	if search != "" {
-		countQuery = countQuery.Where("LOWER(bank_name) LIKE ? OR account_no LIKE ?", "%"+search+"%", "%"+search+"%")
+// Update bank details (UPDATE), penggantian penuh: bank_name dan account_no wajib.
+// If-Match dengan ETag dari GET wajib dikirim.
+func (h *Handler) UpdateBank(c *fiber.Ctx) error {
+	bank, err := h.findBank(c)
+	if err != nil {
+		return err
 	}
-
-	if err := countQuery.Count(&count).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not fetch bank count", nil)
+	version, err := checkIfMatch(c, bank.Version, true)
+	if err != nil {
+		return err
 	}
 
-	return utils.ResponseSuccessManyData(c, "Banks retrieved successfully", banks, page, limit, int(count))
+	var input BankDetailsInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+	return h.replaceBank(c, bank, version, input)
 }
 
-// Update bank details (UPDATE)
-func UpdateBank(c *fiber.Ctx) error {
-	bankID := c.Params("id")
+// PatchBank mengubah sebagian detail bank dengan JSON Merge Patch atau JSON Patch
+func (h *Handler) PatchBank(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderAcceptPatch, patch.Accepted)
 
-	var bank models.Bank
-	if err := database.DB.First(&bank, "id = ?", bankID).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusNotFound, "Bank not found", nil)
+	bank, err := h.findBank(c)
+	if err != nil {
+		return err
+	}
+	version, err := checkIfMatch(c, bank.Version, true)
+	if err != nil {
+		return err
 	}
 
-	var input BankInput
-	if err := c.BodyParser(&input); err != nil {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid input", nil)
+	var input BankDetailsInput
+	if err := applyPatch(c, BankDetailsInput{BankName: bank.BankName, AccountNo: bank.AccountNo}, &input); err != nil {
+		return err
 	}
+	return h.replaceBank(c, bank, version, input)
+}
 
+// replaceBank menyimpan detail bank kalau version-nya masih sama (hasil checkIfMatch)
+func (h *Handler) replaceBank(c *fiber.Ctx, bank *models.Bank, version int64, input BankDetailsInput) error {
+	if strings.TrimSpace(input.BankName) == "" || strings.TrimSpace(input.AccountNo) == "" {
+		return utils.ErrBankFieldsRequired
+	}
 	bank.BankName = input.BankName
 	bank.AccountNo = input.AccountNo
+	bank.Version = version
+
+	err := h.Banks.Update(c.UserContext(), bank)
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return utils.ErrAccountNoInUse
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case err != nil:
+		return utils.ErrBankUpdate.Wrap(err)
+	}
 
-	if err := database.DB.Save(&bank).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not update bank", nil)
+	setETag(c, bank.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgBankUpdated, bank)
+}
+
+// findBank mengambil bank milik user yang sedang login dari path parameter :id.
+// Bank user lain dianggap tidak ada, supaya keberadaannya tidak bocor.
+func (h *Handler) findBank(c *fiber.Ctx) (*models.Bank, error) {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+
+	bankID, ok := paramID(c, "id")
+	if !ok {
+		return nil, utils.ErrBankNotFound
 	}
 
-	return utils.ResponseSuccessOneData(c, "Bank updated successfully", bank)
+	bank, err := h.Banks.FindByID(c.UserContext(), bankID)
+	if err != nil || bank.UserID != user.ID {
+		return nil, utils.ErrBankNotFound
+	}
+	return bank, nil
 }
 
-// Delete bank (DELETE)
-func DeleteBank(c *fiber.Ctx) error {
-	bankID := c.Params("id")
+// Delete bank (DELETE), If-Match wajib
+func (h *Handler) DeleteBank(c *fiber.Ctx) error {
+	bank, err := h.findBank(c)
+	if err != nil {
+		return err
+	}
+	version, err := checkIfMatch(c, bank.Version, true)
+	if err != nil {
+		return err
+	}
 
-	if err := database.DB.Delete(&models.Bank{}, "id = ?", bankID).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not delete bank", nil)
+	err = h.Banks.Delete(c.UserContext(), bank.ID, version)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return utils.ErrPreconditionFailed
+	}
+	if err != nil {
+		return utils.ErrBankDelete.Wrap(err)
 	}
 
-	return utils.ResponseSuccessOneData(c, "Bank deleted successfully",nil)
+	return utils.ResponseSuccessOneData(c, utils.MsgBankDeleted, nil)
 }
 
 // Add money to bank (UPDATE Nominal)
@@ -142,30 +228,45 @@ type AddMoneyInput struct {
 	Amount float64 `json:"amount" validate:"required,min=1"`
 }
 
-func AddMoney(c *fiber.Ctx) error {
-	bankID := c.Params("id")
-
-	var bank models.Bank
-	if err := database.DB.First(&bank, "id = ?", bankID).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusNotFound, "Bank not found", nil)
+// AddMoney menambah saldo secara atomik, jadi setoran paralel tidak saling menimpa.
+// If-Match opsional: penambahan tidak bergantung pada saldo yang dibaca client.
+func (h *Handler) AddMoney(c *fiber.Ctx) error {
+	bank, err := h.findBank(c)
+	if err != nil {
+		return err
+	}
+	if _, err := checkIfMatch(c, bank.Version, false); err != nil {
+		return err
 	}
 
 	var input AddMoneyInput
 	if err := c.BodyParser(&input); err != nil {
-		return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid input", nil)
+		return utils.ErrInvalidInput
+	}
+	// Hanya setoran; jumlah negatif juga akan membuat counter Prometheus panic
+	if input.Amount <= 0 {
+		return utils.ErrInvalidInput
 	}
 
-	// Update nominal balance
-	bank.Nominal += input.Amount
-
-	if err := database.DB.Save(&bank).Error; err != nil {
-		return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not update balance", nil)
+	bank, err = h.Banks.AddMoney(c.UserContext(), bank.ID, input.Amount)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.ErrBankNotFound
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return utils.ErrInsufficientFunds
+	case err != nil:
+		return utils.ErrBalanceUpdate.Wrap(err)
 	}
 
-	return utils.ResponseSuccessOneData(c, "Money added successfully", fiber.Map{
+	metrics.MoneyAddedTotal.WithLabelValues(bank.Currency).Add(input.Amount)
+	logging.FromContext(c.UserContext()).Info("money added", "bank_id", bank.ID, "amount", input.Amount)
+
+	setETag(c, bank.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgMoneyAdded, fiber.Map{
 		"id":         bank.ID,
 		"bank_name":  bank.BankName,
 		"account_no": bank.AccountNo,
 		"nominal":    bank.Nominal,
+		"currency":   bank.Currency,
 	})
 }
@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+
+	"learn_project/catalog"
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// importSyncRows: file dengan baris lebih banyak dari ini diproses sebagai job
+// background (202 + GET /api/jobs/:id), selain itu langsung dalam request
+const importSyncRows = 500
+
+// maxReportErrors membatasi jumlah error baris di laporan import
+const maxReportErrors = 1000
+
+// exportFlushRows adalah jumlah baris export sebelum buffer dikirim ke client
+const exportFlushRows = 100
+
+// ImportReport adalah hasil import (atau dry run) per file
+type ImportReport struct {
+	DryRun          bool             `json:"dry_run"`
+	Total           int              `json:"total"`
+	Created         int              `json:"created"`
+	Updated         int              `json:"updated"`
+	Failed          int              `json:"failed"`
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errors_truncated,omitempty"`
+}
+
+// ImportRowError adalah satu baris yang tidak di-import
+type ImportRowError struct {
+	Row     int             `json:"row"`
+	SKU     string          `json:"sku,omitempty"`
+	Field   string          `json:"field,omitempty"`
+	Code    utils.ErrorCode `json:"code"`
+	Message string          `json:"message"`
+}
+
+// rowError adalah kesalahan validasi satu baris; error lain menghentikan import
+type rowError struct {
+	field  string
+	err    *utils.AppError
+	reason string
+}
+
+func (e *rowError) Error() string {
+	return e.err.Message
+}
+
+func (r *ImportReport) fail(row catalog.Row, err *rowError) {
+	r.Failed++
+	if len(r.Errors) >= maxReportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	message := err.err.Message
+	if err.reason != "" {
+		message += ": " + err.reason
+	}
+	r.Errors = append(r.Errors, ImportRowError{Row: row.Line, SKU: row.SKU, Field: err.field, Code: err.err.Code, Message: message})
+}
+
+// ImportProducts meng-upsert produk berdasarkan SKU dari file CSV atau NDJSON,
+// dikirim sebagai body (Content-Type text/csv atau application/x-ndjson) atau
+// multipart field "file". ?dry_run=true hanya memvalidasi dan mengembalikan laporan.
+// Baris yang tidak valid dilewati dan dicatat di laporan, baris lain tetap disimpan.
+func (h *Handler) ImportProducts(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	data, format, err := importFile(c)
+	if err != nil {
+		return err
+	}
+
+	// File sudah dibatasi SERVER_BODY_LIMIT, jadi semua baris boleh dibaca dulu;
+	// job background tidak boleh memakai body request setelah handler selesai
+	var rows []catalog.Row
+	err = catalog.Read(bytes.NewReader(data), format, func(row catalog.Row) error {
+		rows = append(rows, row)
+		return nil
+	})
+	var fileErr *catalog.FileError
+	if errors.As(err, &fileErr) {
+		return utils.ErrInvalidImportFile.WithData(fiber.Map{"line": fileErr.Line, "reason": fileErr.Reason})
+	}
+	if err != nil {
+		return utils.ErrImportFailed.Wrap(err)
+	}
+
+	dryRun := c.QueryBool("dry_run")
+	if len(rows) > importSyncRows {
+		job := h.Jobs.Start("product_import", user.ID, len(rows), func(ctx context.Context, progress func(int)) (any, error) {
+			return h.importProducts(ctx, user, rows, dryRun, progress)
+		})
+		return utils.ResponseAccepted(c, utils.MsgImportStarted, job)
+	}
+
+	report, err := h.importProducts(c.UserContext(), user, rows, dryRun, func(int) {})
+	if err != nil {
+		return utils.ErrImportFailed.Wrap(err)
+	}
+	if dryRun {
+		return utils.ResponseSuccessOneData(c, utils.MsgImportValidated, report)
+	}
+	return utils.ResponseSuccessOneData(c, utils.MsgImportCompleted, report)
+}
+
+// ExportProducts mengirim semua produk sebagai CSV (default) atau NDJSON.
+// Response di-stream per batch sehingga katalog besar tidak dimuat ke memori sekaligus.
+func (h *Handler) ExportProducts(c *fiber.Ctx) error {
+	format := catalog.Format(c.Query("format", string(catalog.CSV)))
+	if !format.Valid() {
+		return utils.ErrInvalidExportFormat
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="products.`+string(format)+`"`)
+
+	// Writer dijalankan setelah handler selesai, jadi c tidak boleh dipakai di dalamnya.
+	// Status sudah terkirim: error di tengah jalan hanya bisa dicatat dan memotong file.
+	ctx := context.WithoutCancel(c.UserContext())
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer, err := catalog.NewWriter(w, format)
+		if err != nil {
+			return
+		}
+
+		rows := 0
+		err = h.Products.Each(ctx, func(product models.Product) error {
+			if err := writer.Write(exportRecord(product)); err != nil {
+				return err
+			}
+			if rows++; rows%exportFlushRows == 0 {
+				if err := writer.Flush(); err != nil {
+					return err
+				}
+				return w.Flush()
+			}
+			return nil
+		})
+		if err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "export produk terhenti", "rows", rows, "error", err)
+		}
+	})
+	return nil
+}
+
+// importFile mengambil isi file import dan formatnya; ?format= menimpa deteksi otomatis
+func importFile(c *fiber.Ctx) ([]byte, catalog.Format, error) {
+	var data []byte
+	var format catalog.Format
+	if header, err := c.FormFile("file"); err == nil {
+		format = catalog.FormatOf(header.Header.Get(fiber.HeaderContentType), header.Filename)
+		if data, err = readFormFile(header); err != nil {
+			return nil, "", utils.ErrInvalidImportFile
+		}
+	} else {
+		format = catalog.FormatOf(c.Get(fiber.HeaderContentType), "")
+		data = c.Body()
+	}
+
+	if override := catalog.Format(c.Query("format")); override != "" {
+		format = override
+	}
+	if !format.Valid() {
+		return nil, "", utils.ErrUnsupportedImportFormat
+	}
+	return data, format, nil
+}
+
+// importProducts memvalidasi dan (kecuali dry run) menyimpan setiap baris
+func (h *Handler) importProducts(ctx context.Context, user *models.User, rows []catalog.Row, dryRun bool, progress func(int)) (*ImportReport, error) {
+	report := &ImportReport{DryRun: dryRun, Total: len(rows), Errors: []ImportRowError{}}
+	seen := map[string]int{}
+	categories := map[string]*models.Category{}
+
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		product, before, err := h.importRow(ctx, user, row, seen, categories)
+		if err == nil && !dryRun {
+			err = h.saveImportedProduct(ctx, user.ID, product, before)
+		}
+
+		var invalid *rowError
+		switch {
+		case errors.As(err, &invalid):
+			report.fail(row, invalid)
+		case err != nil:
+			return report, err
+		case before == nil:
+			report.Created++
+		default:
+			report.Updated++
+		}
+		progress(i + 1)
+	}
+	return report, nil
+}
+
+// importRow membuat produk baru atau menerapkan baris ke produk dengan SKU yang sama.
+// before adalah salinan produk sebelum diubah, nil untuk produk baru. Produk baru
+// dimiliki user yang meng-import; produk seller lain tidak boleh diubah.
+func (h *Handler) importRow(ctx context.Context, user *models.User, row catalog.Row, seen map[string]int, categories map[string]*models.Category) (product, before *models.Product, err error) {
+	if row.Err != nil {
+		return nil, nil, &rowError{field: row.Err.Field, err: utils.ErrInvalidValue, reason: row.Err.Reason}
+	}
+	sku, err := productSKU(row.SKU)
+	if err != nil {
+		return nil, nil, &rowError{field: "sku", err: utils.ErrInvalidSKU}
+	}
+	if _, ok := seen[*sku]; ok {
+		return nil, nil, &rowError{field: "sku", err: utils.ErrDuplicateRow}
+	}
+	seen[*sku] = row.Line
+
+	product, err = h.Products.FindBySKU(ctx, *sku)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		product = &models.Product{SKU: sku, SellerID: &user.ID, Options: models.ProductOptions{}}
+	case err != nil:
+		return nil, nil, err
+	case !product.EditableBy(user):
+		return nil, nil, &rowError{field: "sku", err: utils.ErrNotProductOwner}
+	default:
+		copied := *product
+		before = &copied
+	}
+
+	if row.Name != nil {
+		product.Name = *row.Name
+	}
+	if row.Description != nil {
+		product.Description = *row.Description
+	}
+	if row.Price != nil {
+		product.Price = *row.Price
+	}
+	if row.HasThreshold {
+		product.LowStockThreshold = row.LowStockThreshold
+	}
+	// Sama seperti API: price wajib untuk produk baru, 0 berarti produk gratis
+	if product.Name == "" || before == nil && row.Price == nil {
+		return nil, nil, &rowError{err: utils.ErrProductNameAndPriceRequired}
+	}
+	if product.Price < 0 {
+		return nil, nil, &rowError{field: "price", err: utils.ErrInvalidPrice}
+	}
+	if product.LowStockThreshold != nil && *product.LowStockThreshold < 0 {
+		return nil, nil, &rowError{field: "low_stock_threshold", err: utils.ErrInvalidStockThreshold}
+	}
+
+	if row.Categories != nil {
+		product.Categories = []models.Category{}
+		for _, slug := range *row.Categories {
+			category, ok := categories[slug]
+			if !ok {
+				found, err := h.Categories.FindBySlug(ctx, slug)
+				if err != nil && !errors.Is(err, repository.ErrNotFound) {
+					return nil, nil, err
+				}
+				category, categories[slug] = found, found
+			}
+			if category == nil {
+				return nil, nil, &rowError{field: "categories", err: utils.ErrUnknownCategory, reason: slug}
+			}
+			product.Categories = append(product.Categories, *category)
+		}
+	}
+	return product, before, nil
+}
+
+func (h *Handler) saveImportedProduct(ctx context.Context, userID uuid.UUID, product, before *models.Product) error {
+	var err error
+	revision := &models.ProductRevision{UserID: &userID}
+	if before == nil {
+		err = h.Products.Create(ctx, product, revision)
+	} else {
+		err = h.Products.Update(ctx, product, revision)
+	}
+	// SKU yang sama dipakai produk yang sudah dihapus, atau import lain yang berjalan bersamaan
+	if errors.Is(err, repository.ErrDuplicate) {
+		return &rowError{field: "sku", err: utils.ErrSKUInUse}
+	}
+	// Produk diubah request lain di antara dibaca dan disimpan
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return &rowError{err: utils.ErrPreconditionFailed}
+	}
+	if err != nil {
+		return err
+	}
+
+	if before != nil {
+		h.publishStockEvents(ctx, before, product)
+	}
+	return nil
+}
+
+func exportRecord(product models.Product) catalog.Record {
+	record := catalog.Record{
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		Categories:        []string{},
+		LowStockThreshold: product.LowStockThreshold,
+		Stock:             product.Stock,
+		Reserved:          product.Reserved,
+	}
+	if product.SKU != nil {
+		record.SKU = *product.SKU
+	}
+	for _, category := range product.Categories {
+		record.Categories = append(record.Categories, category.Slug)
+	}
+	return record
+}
@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxSlugLength = 100
+
+var (
+	slugPattern  = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Struct untuk request body CreateCategory. Slug dibuat dari nama kalau kosong.
+type CreateCategoryInput struct {
+	Name      string     `json:"name"`
+	Slug      string     `json:"slug"`
+	ParentID  *uuid.UUID `json:"parent_id"`
+	SortOrder int        `json:"sort_order"`
+}
+
+// Struct untuk request body UpdateCategory. Field yang tidak dikirim tidak diubah;
+// parent_id "" memindahkan kategori ke root.
+type UpdateCategoryInput struct {
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug"`
+	ParentID  *string `json:"parent_id"`
+	SortOrder *int    `json:"sort_order"`
+}
+
+// CreateCategory membuat kategori baru (admin)
+func (h *Handler) CreateCategory(c *fiber.Ctx) error {
+	var input CreateCategoryInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+
+	category := models.Category{
+		Name:      strings.TrimSpace(input.Name),
+		ParentID:  input.ParentID,
+		SortOrder: input.SortOrder,
+	}
+	if category.Name == "" {
+		return utils.ErrCategoryNameRequired
+	}
+
+	slug, err := makeSlug(input.Slug, category.Name)
+	if err != nil {
+		return err
+	}
+	category.Slug = slug
+
+	if category.ParentID != nil {
+		if _, err := h.Categories.FindByID(c.UserContext(), *category.ParentID); err != nil {
+			return categoryParentError(err)
+		}
+	}
+
+	if err := h.Categories.Create(c.UserContext(), &category); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return utils.ErrSlugInUse
+		}
+		return utils.ErrCategoryCreate.Wrap(err)
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgCategoryCreated, category)
+}
+
+// GetCategories mengembalikan semua kategori dalam bentuk daftar datar
+func (h *Handler) GetCategories(c *fiber.Ctx) error {
+	categories, err := h.Categories.List(c.UserContext())
+	if err != nil {
+		return utils.ErrCategoryList.Wrap(err)
+	}
+	return utils.ResponseSuccessOneData(c, utils.MsgCategoriesRetrieved, categories)
+}
+
+// GetCategoryTree mengembalikan kategori root beserta anak-anaknya secara bersarang
+func (h *Handler) GetCategoryTree(c *fiber.Ctx) error {
+	categories, err := h.Categories.List(c.UserContext())
+	if err != nil {
+		return utils.ErrCategoryList.Wrap(err)
+	}
+	return utils.ResponseSuccessOneData(c, utils.MsgCategoryTreeRetrieved, categoryTree(categories))
+}
+
+// GetCategory mengambil satu kategori berdasarkan ID atau slug
+func (h *Handler) GetCategory(c *fiber.Ctx) error {
+	category, err := h.findCategory(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return utils.ResponseSuccessOneData(c, utils.MsgCategoryRetrieved, category)
+}
+
+// UpdateCategory mengubah kategori (admin). Parent tidak boleh kategori itu sendiri
+// atau turunannya supaya pohon tidak membentuk siklus.
+func (h *Handler) UpdateCategory(c *fiber.Ctx) error {
+	category, err := h.findCategory(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	var input UpdateCategoryInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+
+	if name := strings.TrimSpace(input.Name); name != "" {
+		category.Name = name
+	}
+	if input.Slug != "" {
+		slug, err := makeSlug(input.Slug, "")
+		if err != nil {
+			return err
+		}
+		category.Slug = slug
+	}
+	if input.SortOrder != nil {
+		category.SortOrder = *input.SortOrder
+	}
+
+	if input.ParentID != nil {
+		if *input.ParentID == "" {
+			category.ParentID = nil
+		} else {
+			parentID, err := uuid.Parse(*input.ParentID)
+			if err != nil {
+				return utils.ErrInvalidParentCategory
+			}
+			subtree, err := h.Categories.Subtree(c.UserContext(), category.ID)
+			if err != nil {
+				return utils.ErrCategoryUpdate.Wrap(err)
+			}
+			if slices.Contains(subtree, parentID) {
+				return utils.ErrInvalidParentCategory
+			}
+			if _, err := h.Categories.FindByID(c.UserContext(), parentID); err != nil {
+				return categoryParentError(err)
+			}
+			category.ParentID = &parentID
+		}
+	}
+
+	if err := h.Categories.Update(c.UserContext(), category); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return utils.ErrSlugInUse
+		}
+		return utils.ErrCategoryUpdate.Wrap(err)
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgCategoryUpdated, category)
+}
+
+// DeleteCategory menghapus kategori (admin). Relasi ke produk ikut terhapus,
+// tapi kategori yang masih punya subkategori ditolak.
+func (h *Handler) DeleteCategory(c *fiber.Ctx) error {
+	category, err := h.findCategory(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := h.Categories.Delete(c.UserContext(), category.ID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInUse):
+			return utils.ErrCategoryHasChildren
+		case errors.Is(err, repository.ErrNotFound):
+			return utils.ErrCategoryNotFound
+		}
+		return utils.ErrCategoryDelete.Wrap(err)
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgCategoryDeleted, nil)
+}
+
+// findCategory mencari kategori berdasarkan ID, atau slug kalau bukan UUID
+func (h *Handler) findCategory(ctx context.Context, ref string) (*models.Category, error) {
+	var category *models.Category
+	var err error
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		category, err = h.Categories.FindByID(ctx, id)
+	} else {
+		category, err = h.Categories.FindBySlug(ctx, ref)
+	}
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, utils.ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, utils.ErrCategoryList.Wrap(err)
+	}
+	return category, nil
+}
+
+// productCategories memuat kategori untuk category_ids produk; semua ID harus ada
+func (h *Handler) productCategories(ctx context.Context, ids []uuid.UUID) ([]models.Category, error) {
+	categories, err := h.Categories.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, utils.ErrCategoryList.Wrap(err)
+	}
+
+	for _, id := range ids {
+		if !slices.ContainsFunc(categories, func(category models.Category) bool { return category.ID == id }) {
+			return nil, utils.ErrUnknownCategory.WithData(fiber.Map{"category_id": id})
+		}
+	}
+	return categories, nil
+}
+
+func categoryParentError(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return utils.ErrInvalidParentCategory
+	}
+	return utils.ErrCategoryList.Wrap(err)
+}
+
+// makeSlug memvalidasi slug kategori atau toko, atau membuatnya dari nama kalau slug kosong
+// ("Kopi & Teh" menjadi "kopi-teh")
+func makeSlug(slug, name string) (string, error) {
+	if slug == "" {
+		slug = strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	}
+	if len(slug) > maxSlugLength || !slugPattern.MatchString(slug) {
+		return "", utils.ErrInvalidSlug
+	}
+	return slug, nil
+}
+
+// categoryTree menyusun daftar kategori (sudah terurut) menjadi pohon.
+// Urutan anak mengikuti urutan daftar, yaitu sort_order lalu nama.
+func categoryTree(categories []models.Category) []*models.Category {
+	nodes := make(map[uuid.UUID]*models.Category, len(categories))
+	for i := range categories {
+		nodes[categories[i].ID] = &categories[i]
+	}
+
+	roots := []*models.Category{}
+	for i := range categories {
+		node := &categories[i]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"learn_project/models"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// etag adalah ETag kuat untuk Version sebuah resource, mis. "3"
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag mengirim ETag supaya client bisa memakainya di If-Match berikutnya
+func setETag(c *fiber.Ctx, version int64) {
+	c.Set(fiber.HeaderETag, etag(version))
+}
+
+// notModified mengirim ETag lalu mengecek If-None-Match; true berarti client sudah
+// punya versi terbaru dan handler cukup membalas 304 tanpa body.
+// c.Fresh() tidak dipakai karena menganggap If-Modified-Since saja sudah fresh.
+func notModified(c *fiber.Ctx, version int64) bool {
+	setETag(c, version)
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	return header != "" && etagMatches(header, etag(version), false)
+}
+
+// checkIfMatch mengecek If-Match terhadap versi resource yang tersimpan: 428 kalau
+// header wajib tapi tidak dikirim, 412 kalau tidak ada ETag yang cocok. Endpoint
+// dengan required false tetap mengecek header kalau dikirim.
+//
+// Hasilnya adalah version yang harus dicocokkan lagi oleh repository saat menyimpan
+// (data bisa berubah setelah dicek di sini): version itu sendiri, atau 0 (tanpa
+// pengecekan) untuk If-Match: * dan header opsional yang tidak dikirim.
+func checkIfMatch(c *fiber.Ctx, version int64, required bool) (int64, error) {
+	header := c.Get(fiber.HeaderIfMatch)
+	switch {
+	case header == "" && required:
+		return 0, utils.ErrPreconditionRequired
+	case header == "" || strings.TrimSpace(header) == "*":
+		return 0, nil
+	case !etagMatches(header, etag(version), true):
+		return 0, utils.ErrPreconditionFailed
+	}
+	return version, nil
+}
+
+// checkProductMatch mewajibkan If-Match dengan ETag produk induk untuk perubahan
+// varian dan gambar, yang ikut menaikkan version produk. Version hasilnya dicocokkan
+// lagi oleh repository varian/gambar di transaksi yang sama dengan perubahannya.
+func checkProductMatch(c *fiber.Ctx, product *models.Product) (int64, error) {
+	return checkIfMatch(c, product.Version, true)
+}
+
+// etagMatches mencocokkan daftar ETag dari header (dipisah koma, "*" cocok dengan apa
+// pun) dengan tag. If-Match memakai perbandingan kuat sehingga ETag lemah (W/) tidak
+// pernah cocok, If-None-Match memakai perbandingan lemah (RFC 9110 bagian 8.8.3.2).
+func etagMatches(header, tag string, strong bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak, ok := strings.CutPrefix(candidate, "W/"); ok {
+			if strong {
+				continue
+			}
+			candidate = weak
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
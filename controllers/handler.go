@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"learn_project/events"
+	"learn_project/health"
+	"learn_project/jobs"
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/storage"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Handler menyimpan semua dependency controller. Repository di-inject dari luar
+// (GORM di produksi, in-memory di test) sehingga controller tidak memakai database.DB langsung.
+type Handler struct {
+	Users      repository.UserRepository
+	Banks      repository.BankRepository
+	Products   repository.ProductRepository
+	Categories repository.CategoryRepository
+	Variants   repository.VariantRepository
+	Images     repository.ImageRepository
+	Sellers    repository.SellerRepository
+	JWT        *utils.JWTManager
+
+	// Blobs menyimpan file gambar produk; MaxImageSize adalah batas byte per upload (0 = tanpa batas)
+	Blobs        storage.BlobStore
+	MaxImageSize int64
+
+	// Jobs menjalankan pekerjaan panjang (import katalog besar) di background
+	Jobs *jobs.Manager
+
+	// Events menerima event domain (mis. stok menipis); nil berarti event tidak dikirim
+	Events events.Publisher
+
+	// ReadinessChecks dijalankan oleh GET /readyz (mis. ping database, migrasi)
+	ReadinessChecks []health.Check
+}
+
+// currentUser mengambil user yang sedang login berdasarkan email dari token
+func (h *Handler) currentUser(c *fiber.Ctx) (*models.User, error) {
+	email, ok := c.Locals("email").(string)
+	if !ok {
+		return nil, utils.ErrUnauthorized
+	}
+
+	user, err := h.Users.FindByEmail(c.UserContext(), email)
+	if err != nil {
+		return nil, utils.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// AdminOnly adalah middleware untuk route yang hanya boleh diakses admin.
+// Dipasang setelah middleware.Protected.
+func (h *Handler) AdminOnly(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	if user.Role != models.RoleAdmin {
+		return utils.ErrForbidden
+	}
+	return c.Next()
+}
+
+// paramID membaca path parameter berupa UUID; ok false kalau formatnya tidak valid
+func paramID(c *fiber.Ctx, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Params(name))
+	return id, err == nil
+}
@@ -0,0 +1,2056 @@
+package controllers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"learn_project/config"
+	"learn_project/controllers"
+	"learn_project/database"
+	"learn_project/events"
+	"learn_project/health"
+	"learn_project/jobs"
+	"learn_project/migrations"
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/routes"
+	"learn_project/storage"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func init() {
+	// bcrypt cost 14 terlalu lambat untuk test
+	models.PasswordCost = bcrypt.MinCost
+}
+
+type testEnv struct {
+	app     *fiber.App
+	handler *controllers.Handler
+	events  *eventRecorder
+}
+
+// eventRecorder menyimpan event yang dipublish handler
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *eventRecorder) Publish(_ context.Context, event events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// take mengembalikan tipe event yang tercatat sejak take terakhir
+func (r *eventRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	types := []string{}
+	for _, event := range r.events {
+		types = append(types, event.Type)
+	}
+	r.events = nil
+	return types
+}
+
+// backends adalah implementasi repository yang diuji dengan suite yang sama:
+// in-memory, dan GORM di atas sqlite :memory: dengan migrasi SQL sungguhan
+var backends = []struct {
+	name string
+	new  func(t *testing.T) *controllers.Handler
+}{
+	{"memory", newMemoryHandler},
+	{"sqlite", newSQLiteHandler},
+}
+
+func newMemoryHandler(t *testing.T) *controllers.Handler {
+	categories := repository.NewMemoryCategoryRepository()
+	products := repository.NewMemoryProductRepository(categories)
+	return &controllers.Handler{
+		Users:      repository.NewMemoryUserRepository(),
+		Banks:      repository.NewMemoryBankRepository(),
+		Products:   products,
+		Categories: categories,
+		Variants:   repository.NewMemoryVariantRepository(products),
+		Images:     repository.NewMemoryImageRepository(products),
+		Sellers:    repository.NewMemorySellerRepository(),
+	}
+}
+
+func newSQLiteHandler(t *testing.T) *controllers.Handler {
+	t.Helper()
+
+	db, err := database.Open(config.DatabaseConfig{Driver: "sqlite", URL: ":memory:"}, config.LogConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrationFS, err := migrations.FS("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := database.NewMigrator(db, migrationFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return &controllers.Handler{
+		Users:           repository.NewGormUserRepository(db),
+		Banks:           repository.NewGormBankRepository(db),
+		Products:        repository.NewGormProductRepository(db),
+		Categories:      repository.NewGormCategoryRepository(db),
+		Variants:        repository.NewGormVariantRepository(db),
+		Images:          repository.NewGormImageRepository(db),
+		Sellers:         repository.NewGormSellerRepository(db),
+		ReadinessChecks: []health.Check{database.PingCheck(db), database.MigrationsCheck(migrator)},
+	}
+}
+
+// forEachBackend menjalankan test yang sama untuk setiap backend repository
+func forEachBackend(t *testing.T, test func(t *testing.T, env *testEnv)) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			h := backend.new(t)
+			h.JWT = utils.NewJWTManager(config.JWTConfig{
+				Secret:        "test-access-secret-0123456789abcdef",
+				RefreshSecret: "test-refresh-secret-0123456789abcdef",
+			})
+			recorder := &eventRecorder{}
+			h.Events = recorder
+			blobs, err := storage.NewLocal(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.Blobs, h.MaxImageSize = blobs, 64*1024
+			h.Jobs = jobs.NewManager()
+			t.Cleanup(func() { h.Jobs.Close(context.Background()) })
+
+			app := fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+			routes.SetupRoutes(app, h)
+			test(t, &testEnv{app: app, handler: h, events: recorder})
+		})
+	}
+}
+
+type response struct {
+	Status int
+	Header http.Header
+	Body   map[string]any
+}
+
+func (r response) data() map[string]any {
+	data, _ := r.Body["data"].(map[string]any)
+	return data
+}
+
+func (r response) list() []any {
+	list, _ := r.Body["data"].([]any)
+	return list
+}
+
+func (r response) code() string {
+	code, _ := r.Body["code"].(string)
+	return code
+}
+
+func (e *testEnv) do(t *testing.T, method, path, token string, body any) response {
+	t.Helper()
+	return e.send(t, e.request(t, method, path, token, body))
+}
+
+// doMatch seperti do ditambah header If-Match, berisi ETag dari GET atau "*" untuk
+// test yang tidak menguji concurrency
+func (e *testEnv) doMatch(t *testing.T, method, path, token, ifMatch string, body any) response {
+	t.Helper()
+
+	req := e.request(t, method, path, token, body)
+	req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+	return e.send(t, req)
+}
+
+func (e *testEnv) request(t *testing.T, method, path, token string, body any) *http.Request {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	if token != "" {
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	}
+	return req
+}
+
+// upload mengirim file sebagai multipart form field "image"
+func (e *testEnv) upload(t *testing.T, path, token, ifMatch string, file []byte) response {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", "foto.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(file)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set(fiber.HeaderContentType, writer.FormDataContentType())
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+	return e.send(t, req)
+}
+
+func (e *testEnv) send(t *testing.T, req *http.Request) response {
+	t.Helper()
+
+	method, path := req.Method, req.URL.Path
+	resp, err := e.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	out := response{Status: resp.StatusCode, Header: resp.Header}
+	raw, _ := io.ReadAll(resp.Body)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &out.Body); err != nil {
+			t.Fatalf("%s %s: body bukan JSON: %s", method, path, raw)
+		}
+	}
+	return out
+}
+
+func expect(t *testing.T, r response, status int, code string) {
+	t.Helper()
+	if r.Status != status {
+		t.Fatalf("status = %d, want %d (body %v)", r.Status, status, r.Body)
+	}
+	if code != "" && r.code() != code {
+		t.Fatalf("code = %q, want %q (body %v)", r.code(), code, r.Body)
+	}
+}
+
+// login mendaftarkan user baru lalu mengembalikan access token-nya
+func (e *testEnv) login(t *testing.T, email string) string {
+	t.Helper()
+
+	r := e.do(t, http.MethodPost, "/register", "", fiber.Map{"name": "Budi", "email": email, "password": "rahasia123"})
+	expect(t, r, http.StatusOK, string(utils.MsgUserRegistered))
+
+	r = e.do(t, http.MethodPost, "/login", "", fiber.Map{"email": email, "password": "rahasia123"})
+	expect(t, r, http.StatusOK, string(utils.MsgLoginSuccess))
+	return r.data()["access_token"].(string)
+}
+
+// promote menjadikan user admin (di produksi dilakukan manual lewat SQL)
+func (e *testEnv) promote(t *testing.T, email string) {
+	t.Helper()
+
+	user, err := e.handler.Users.FindByEmail(context.Background(), email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user.Role = models.RoleAdmin
+	if err := e.handler.Users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHealthRoutes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		expect(t, env.do(t, http.MethodGet, "/healthz", "", nil), http.StatusOK, "")
+
+		r := env.do(t, http.MethodGet, "/version", "", nil)
+		expect(t, r, http.StatusOK, "")
+		if _, ok := r.Body["go_version"]; !ok {
+			t.Fatalf("/version tanpa go_version: %v", r.Body)
+		}
+
+		r = env.do(t, http.MethodGet, "/readyz", "", nil)
+		expect(t, r, http.StatusOK, "")
+		if r.Body["status"] != "ok" {
+			t.Fatalf("readyz status = %v", r.Body["status"])
+		}
+	})
+}
+
+func TestReadyzFailingCheck(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		env.handler.ReadinessChecks = []health.Check{
+			{Name: "database", Run: func(context.Context) error { return nil }},
+			{Name: "migrations", Run: func(context.Context) error { return errors.New("1 migration(s) pending") }},
+		}
+
+		r := env.do(t, http.MethodGet, "/readyz", "", nil)
+		expect(t, r, http.StatusServiceUnavailable, "")
+
+		checks := r.Body["checks"].(map[string]any)
+		if got := checks["database"].(map[string]any)["status"]; got != "ok" {
+			t.Errorf("database = %v, want ok", got)
+		}
+		migrations := checks["migrations"].(map[string]any)
+		if migrations["status"] != "fail" || migrations["error"] != "1 migration(s) pending" {
+			t.Errorf("migrations = %v", migrations)
+		}
+	})
+}
+
+func TestRegister(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		r := env.do(t, http.MethodPost, "/register", "", fiber.Map{"name": "Budi", "email": "budi@example.com", "password": "rahasia123"})
+		expect(t, r, http.StatusOK, string(utils.MsgUserRegistered))
+		if _, ok := r.data()["password"]; ok {
+			t.Fatal("response register tidak boleh berisi password")
+		}
+
+		r = env.do(t, http.MethodPost, "/register", "", fiber.Map{"name": "Budi", "email": "budi@example.com", "password": "lainnya123"})
+		expect(t, r, http.StatusConflict, string(utils.ErrEmailInUse.Code))
+
+		r = env.do(t, http.MethodPost, "/register", "", fiber.Map{"email": "kosong@example.com"})
+		expect(t, r, http.StatusBadRequest, string(utils.ErrMissingFields.Code))
+	})
+}
+
+func TestLogin(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+		if token == "" {
+			t.Fatal("access token kosong")
+		}
+
+		r := env.do(t, http.MethodPost, "/login", "", fiber.Map{"email": "budi@example.com", "password": "salah"})
+		expect(t, r, http.StatusUnauthorized, string(utils.ErrInvalidCredentials.Code))
+
+		r = env.do(t, http.MethodPost, "/login", "", fiber.Map{"email": "tidakada@example.com", "password": "rahasia123"})
+		expect(t, r, http.StatusUnauthorized, string(utils.ErrInvalidCredentials.Code))
+	})
+}
+
+func TestGetUser(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		r := env.do(t, http.MethodGet, "/api/user", token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgUserRetrieved))
+		if r.data()["email"] != "budi@example.com" {
+			t.Fatalf("email = %v", r.data()["email"])
+		}
+
+		expect(t, env.do(t, http.MethodGet, "/api/user", "", nil), http.StatusUnauthorized, string(utils.ErrUnauthorized.Code))
+		expect(t, env.do(t, http.MethodGet, "/api/user", "bukan-token", nil), http.StatusUnauthorized, string(utils.ErrInvalidToken.Code))
+	})
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		for _, tc := range []struct {
+			language, want, success, notFound string
+		}{
+			{"", "en", "Products retrieved successfully", "Product not found"},
+			{"id-ID,id;q=0.9,en;q=0.8", "id", "Data produk berhasil diambil", "Produk tidak ditemukan"},
+			{"fr, en;q=0.5", "en", "Products retrieved successfully", "Product not found"},
+			{"fr", "en", "Products retrieved successfully", "Product not found"},
+		} {
+			get := func(path string) response {
+				t.Helper()
+				req := env.request(t, http.MethodGet, path, token, nil)
+				if tc.language != "" {
+					req.Header.Set(fiber.HeaderAcceptLanguage, tc.language)
+				}
+				r := env.send(t, req)
+				if r.Header.Get(fiber.HeaderContentLanguage) != tc.want || !strings.Contains(r.Header.Get(fiber.HeaderVary), fiber.HeaderAcceptLanguage) {
+					t.Fatalf("Accept-Language %q: headers = %v", tc.language, r.Header)
+				}
+				return r
+			}
+
+			if r := get("/api/products"); r.Body["message"] != tc.success {
+				t.Errorf("Accept-Language %q: message = %v", tc.language, r.Body["message"])
+			}
+			r := get("/api/products/" + uuid.NewString())
+			expect(t, r, http.StatusNotFound, string(utils.ErrProductNotFound.Code))
+			if r.Body["message"] != tc.notFound {
+				t.Errorf("Accept-Language %q: message = %v", tc.language, r.Body["message"])
+			}
+		}
+	})
+}
+
+func TestProductRoutes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Kopi", "description": "Arabika", "price": 25000})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		id := r.data()["id"].(string)
+
+		env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Teh", "price": 15000})
+
+		r = env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Gratis"})
+		expect(t, r, http.StatusBadRequest, string(utils.ErrProductNameAndPriceRequired.Code))
+
+		r = env.do(t, http.MethodGet, "/api/products?page=1&limit=10", token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductsRetrieved))
+		if len(r.list()) != 2 || r.Body["count"] != float64(2) {
+			t.Fatalf("list = %v, count = %v", r.list(), r.Body["count"])
+		}
+
+		r = env.do(t, http.MethodGet, "/api/products?search=kop", token, nil)
+		if len(r.list()) != 1 {
+			t.Fatalf("search kop = %d produk, want 1", len(r.list()))
+		}
+
+		// Wildcard LIKE dari input dicari apa adanya
+		r = env.do(t, http.MethodGet, "/api/products?search=%25", token, nil)
+		if len(r.list()) != 0 {
+			t.Fatalf("search %% = %d produk, want 0", len(r.list()))
+		}
+
+		r = env.do(t, http.MethodGet, "/api/products?limit=1&page=2", token, nil)
+		if len(r.list()) != 1 || r.Body["count"] != float64(2) {
+			t.Fatalf("halaman 2 = %v, count = %v", r.list(), r.Body["count"])
+		}
+
+		r = env.do(t, http.MethodGet, "/api/products/"+id, token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductRetrieved))
+		if r.data()["name"] != "Kopi" {
+			t.Fatalf("name = %v", r.data()["name"])
+		}
+
+		r = env.doMatch(t, http.MethodPut, "/api/products/"+id, token, "*", fiber.Map{"name": "Kopi Susu", "price": 30000})
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		if r.data()["name"] != "Kopi Susu" || r.data()["price"] != float64(30000) {
+			t.Fatalf("update = %v", r.data())
+		}
+
+		expect(t, env.doMatch(t, http.MethodDelete, "/api/products/"+id, token, "*", nil), http.StatusOK, string(utils.MsgProductDeleted))
+
+		notFound := string(utils.ErrProductNotFound.Code)
+		expect(t, env.do(t, http.MethodGet, "/api/products/"+id, token, nil), http.StatusNotFound, notFound)
+		expect(t, env.do(t, http.MethodPut, "/api/products/"+id, token, fiber.Map{"name": "X"}), http.StatusNotFound, notFound)
+		expect(t, env.do(t, http.MethodDelete, "/api/products/"+id, token, nil), http.StatusNotFound, notFound)
+		expect(t, env.do(t, http.MethodGet, "/api/products/bukan-uuid", token, nil), http.StatusNotFound, notFound)
+
+		expect(t, env.do(t, http.MethodGet, "/api/products", "", nil), http.StatusUnauthorized, string(utils.ErrUnauthorized.Code))
+	})
+}
+
+func TestBankRoutes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "BCA", "account_no": "111"})
+		expect(t, r, http.StatusOK, string(utils.MsgBankAdded))
+		id := r.data()["id"].(string)
+		if r.data()["currency"] != "IDR" {
+			t.Fatalf("currency default = %v, want IDR", r.data()["currency"])
+		}
+
+		r = env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "Mandiri", "account_no": "111"})
+		expect(t, r, http.StatusConflict, string(utils.ErrAccountNoInUse.Code))
+
+		r = env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "Mandiri", "account_no": "222", "currency": "rupiah"})
+		expect(t, r, http.StatusBadRequest, string(utils.ErrInvalidCurrency.Code))
+
+		r = env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "Mandiri", "account_no": "222", "currency": "usd"})
+		expect(t, r, http.StatusOK, string(utils.MsgBankAdded))
+		if r.data()["currency"] != "USD" {
+			t.Fatalf("currency = %v, want USD", r.data()["currency"])
+		}
+
+		// Bank milik user lain tidak ikut di daftar
+		other := env.login(t, "ani@example.com")
+		env.do(t, http.MethodPost, "/api/bank", other, fiber.Map{"bank_name": "BNI", "account_no": "333"})
+
+		r = env.do(t, http.MethodGet, "/api/banks", token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgBanksRetrieved))
+		if len(r.list()) != 2 || r.Body["count"] != float64(2) {
+			t.Fatalf("banks = %v, count = %v", r.list(), r.Body["count"])
+		}
+
+		r = env.do(t, http.MethodGet, "/api/banks?search=mand", token, nil)
+		if len(r.list()) != 1 {
+			t.Fatalf("search mand = %d bank, want 1", len(r.list()))
+		}
+
+		r = env.doMatch(t, http.MethodPut, "/api/bank/"+id, token, "*", fiber.Map{"bank_name": "BCA Syariah", "account_no": "112"})
+		expect(t, r, http.StatusOK, string(utils.MsgBankUpdated))
+		if r.data()["bank_name"] != "BCA Syariah" || r.data()["account_no"] != "112" {
+			t.Fatalf("update = %v", r.data())
+		}
+
+		r = env.doMatch(t, http.MethodPut, "/api/bank/"+id, token, "*", fiber.Map{"bank_name": "BCA", "account_no": "222"})
+		expect(t, r, http.StatusConflict, string(utils.ErrAccountNoInUse.Code))
+
+		r = env.do(t, http.MethodPut, "/api/bank/"+id+"/add-money", token, fiber.Map{"amount": 50000})
+		expect(t, r, http.StatusOK, string(utils.MsgMoneyAdded))
+		r = env.do(t, http.MethodPut, "/api/bank/"+id+"/add-money", token, fiber.Map{"amount": 25000})
+		if r.data()["nominal"] != float64(75000) {
+			t.Fatalf("nominal = %v, want 75000", r.data()["nominal"])
+		}
+		// Hanya setoran positif
+		for _, amount := range []float64{-100000, 0} {
+			r = env.do(t, http.MethodPut, "/api/bank/"+id+"/add-money", token, fiber.Map{"amount": amount})
+			expect(t, r, http.StatusBadRequest, string(utils.ErrInvalidInput.Code))
+		}
+		// Repository sendiri juga menolak saldo negatif
+		if _, err := env.handler.Banks.AddMoney(context.Background(), uuid.MustParse(id), -75001); !errors.Is(err, repository.ErrInsufficientFunds) {
+			t.Fatalf("AddMoney negatif: err = %v", err)
+		}
+		r = env.do(t, http.MethodGet, "/api/bank/"+id, token, nil)
+		if r.data()["nominal"] != float64(75000) {
+			t.Fatalf("nominal = %v, want 75000", r.data()["nominal"])
+		}
+
+		expect(t, env.doMatch(t, http.MethodDelete, "/api/bank/"+id, token, "*", nil), http.StatusOK, string(utils.MsgBankDeleted))
+
+		notFound := string(utils.ErrBankNotFound.Code)
+		missing := uuid.NewString()
+		expect(t, env.do(t, http.MethodPut, "/api/bank/"+id, token, fiber.Map{"bank_name": "X"}), http.StatusNotFound, notFound)
+		expect(t, env.do(t, http.MethodPut, "/api/bank/"+missing+"/add-money", token, fiber.Map{"amount": 1}), http.StatusNotFound, notFound)
+		expect(t, env.do(t, http.MethodDelete, "/api/bank/bukan-uuid", token, nil), http.StatusNotFound, notFound)
+
+		r = env.do(t, http.MethodGet, "/api/banks", token, nil)
+		if len(r.list()) != 1 {
+			t.Fatalf("setelah delete = %d bank, want 1", len(r.list()))
+		}
+
+		expect(t, env.do(t, http.MethodGet, "/api/banks", "", nil), http.StatusUnauthorized, string(utils.ErrUnauthorized.Code))
+	})
+}
+
+func TestAdminPoolStats(t *testing.T) {
+	db, err := database.Open(config.DatabaseConfig{Driver: "sqlite", URL: ":memory:"}, config.LogConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	admin := fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+	routes.SetupAdminRoutes(admin, sqlDB)
+	env := &testEnv{app: admin}
+
+	r := env.do(t, http.MethodGet, "/db/stats", "", nil)
+	expect(t, r, http.StatusOK, "")
+	if r.Body["max_open_connections"] != float64(1) {
+		t.Fatalf("max_open_connections = %v, want 1 (sqlite)", r.Body["max_open_connections"])
+	}
+
+	resp, err := admin.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/metrics status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		var created []string
+		for _, name := range []string{"A", "B", "C", "D", "E"} {
+			r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": name, "price": 1000})
+			created = append(created, r.data()["id"].(string))
+		}
+
+		// Halaman pertama tanpa cursor tetap mode page, tapi sudah memberi next_cursor
+		r := env.do(t, http.MethodGet, "/api/products?limit=2", token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductsRetrieved))
+		if r.Body["page"] != float64(1) || r.Body["next_cursor"] == nil {
+			t.Fatalf("halaman pertama: page = %v, next_cursor = %v", r.Body["page"], r.Body["next_cursor"])
+		}
+
+		var seen []string
+		for _, item := range r.list() {
+			seen = append(seen, item.(map[string]any)["id"].(string))
+		}
+
+		// Produk baru di tengah jalan muncul di akhir, tanpa duplikat atau yang terlewat
+		r2 := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "F", "price": 1000})
+		created = append(created, r2.data()["id"].(string))
+
+		for cursor := r.Body["next_cursor"]; cursor != nil; cursor = r.Body["next_cursor"] {
+			r = env.do(t, http.MethodGet, "/api/products?limit=2&cursor="+cursor.(string), token, nil)
+			expect(t, r, http.StatusOK, string(utils.MsgProductsRetrieved))
+			if _, ok := r.Body["page"]; ok {
+				t.Fatal("mode cursor tidak boleh mengirim page")
+			}
+			for _, item := range r.list() {
+				seen = append(seen, item.(map[string]any)["id"].(string))
+			}
+		}
+
+		if len(seen) != len(created) {
+			t.Fatalf("dilihat %d produk, want %d", len(seen), len(created))
+		}
+		for i := range created {
+			if seen[i] != created[i] {
+				t.Fatalf("urutan ke-%d = %s, want %s", i, seen[i], created[i])
+			}
+		}
+
+		// Page terakhir tepat habis: next_cursor null
+		r = env.do(t, http.MethodGet, "/api/products?limit=3&page=2", token, nil)
+		if len(r.list()) != 3 || r.Body["next_cursor"] != nil {
+			t.Fatalf("halaman terakhir: %d produk, next_cursor = %v", len(r.list()), r.Body["next_cursor"])
+		}
+
+		env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "BCA", "account_no": "111"})
+		env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "BNI", "account_no": "222"})
+		r = env.do(t, http.MethodGet, "/api/banks?limit=1", token, nil)
+		r = env.do(t, http.MethodGet, "/api/banks?limit=1&cursor="+r.Body["next_cursor"].(string), token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgBanksRetrieved))
+		if len(r.list()) != 1 || r.list()[0].(map[string]any)["bank_name"] != "BNI" || r.Body["next_cursor"] != nil {
+			t.Fatalf("bank halaman kedua = %v, next_cursor = %v", r.list(), r.Body["next_cursor"])
+		}
+	})
+}
+
+func TestPaginationValidation(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		for _, query := range []string{"limit=0", "limit=101", "limit=1000000", "page=0", "page=-1", "page=abc", "limit=x"} {
+			expect(t, env.do(t, http.MethodGet, "/api/products?"+query, token, nil), http.StatusBadRequest, string(utils.ErrInvalidPagination.Code))
+			expect(t, env.do(t, http.MethodGet, "/api/banks?"+query, token, nil), http.StatusBadRequest, string(utils.ErrInvalidPagination.Code))
+		}
+		for _, cursor := range []string{"bukan-cursor", "e30"} {
+			expect(t, env.do(t, http.MethodGet, "/api/products?cursor="+cursor, token, nil), http.StatusBadRequest, string(utils.ErrInvalidCursor.Code))
+		}
+
+		expect(t, env.do(t, http.MethodGet, "/api/products?limit=100", token, nil), http.StatusOK, string(utils.MsgProductsRetrieved))
+	})
+}
+
+func names(r response, key string) []string {
+	var out []string
+	for _, item := range r.list() {
+		out = append(out, item.(map[string]any)[key].(string))
+	}
+	return out
+}
+
+func expectNames(t *testing.T, r response, key string, want ...string) {
+	t.Helper()
+	expect(t, r, http.StatusOK, "")
+	got := names(r, key)
+	if len(got) != len(want) {
+		t.Fatalf("%s = %v, want %v", key, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestProductSortAndFilter(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		ids := map[string]string{}
+		for _, p := range []fiber.Map{
+			{"name": "Kopi", "price": 25000, "description": "Arabika Gayo"},
+			{"name": "Teh", "price": 15000, "description": "Melati"},
+			{"name": "Susu", "price": 30000, "description": "Sapi segar"},
+			{"name": "Roti", "price": 10000, "description": "Gandum 100%"},
+		} {
+			r := env.do(t, http.MethodPost, "/api/products", token, p)
+			ids[p["name"].(string)] = r.data()["id"].(string)
+		}
+
+		get := func(query string) response {
+			return env.do(t, http.MethodGet, "/api/products?"+query, token, nil)
+		}
+
+		expectNames(t, get(""), "name", "Kopi", "Teh", "Susu", "Roti")
+		expectNames(t, get("sort=price"), "name", "Roti", "Teh", "Kopi", "Susu")
+		expectNames(t, get("sort=-price"), "name", "Susu", "Kopi", "Teh", "Roti")
+		expectNames(t, get("sort=name,-created_at"), "name", "Kopi", "Roti", "Susu", "Teh")
+		expectNames(t, get("sort=price&min_price=12000&max_price=25000"), "name", "Teh", "Kopi")
+		expectNames(t, get("description=ARAB"), "name", "Kopi")
+		expectNames(t, get("description=100%25"), "name", "Roti")
+
+		today := time.Now().Format(time.DateOnly)
+		tomorrow := time.Now().AddDate(0, 0, 1).Format(time.DateOnly)
+		expectNames(t, get("created_from="+today+"&created_to="+today), "name", "Kopi", "Teh", "Susu", "Roti")
+		expectNames(t, get("created_from="+tomorrow), "name")
+
+		// Cursor mengikuti sort yang dipakai, dan ditolak kalau sort-nya diganti
+		r := get("sort=-price&limit=3")
+		expectNames(t, r, "name", "Susu", "Kopi", "Teh")
+		cursor := r.Body["next_cursor"].(string)
+		expectNames(t, get("sort=-price&limit=3&cursor="+cursor), "name", "Roti")
+		expect(t, get("sort=price&limit=3&cursor="+cursor), http.StatusBadRequest, string(utils.ErrInvalidCursor.Code))
+
+		for query, code := range map[string]*utils.AppError{
+			"sort=password":                 utils.ErrInvalidSort,
+			"sort=price,-price":             utils.ErrInvalidSort,
+			"min_price=murah":               utils.ErrInvalidFilter,
+			"min_price=20000&max_price=100": utils.ErrInvalidFilter,
+			"created_from=kemarin":          utils.ErrInvalidFilter,
+			"include_deleted=mungkin":       utils.ErrInvalidFilter,
+		} {
+			r := get(query)
+			expect(t, r, http.StatusBadRequest, string(code.Code))
+			if r.data()["param"] == nil || r.data()["reason"] == nil {
+				t.Fatalf("%s: error tanpa param/reason: %v", query, r.Body)
+			}
+		}
+
+		// include_deleted hanya untuk admin
+		expect(t, env.doMatch(t, http.MethodDelete, "/api/products/"+ids["Teh"], token, "*", nil), http.StatusOK, "")
+		expectNames(t, get("sort=price"), "name", "Roti", "Kopi", "Susu")
+		expect(t, get("include_deleted=true"), http.StatusForbidden, string(utils.ErrForbidden.Code))
+
+		env.promote(t, "budi@example.com")
+		r = get("sort=price&include_deleted=true")
+		expectNames(t, r, "name", "Roti", "Teh", "Kopi", "Susu")
+		if r.list()[1].(map[string]any)["deleted_at"] == nil {
+			t.Fatal("produk terhapus tanpa deleted_at")
+		}
+	})
+}
+
+// Di sqlite dan in-memory ?q= memakai fallback LIKE: semua kata harus ada di
+// name/description, rank = jumlah kata yang ada di name.
+func TestProductTextSearch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		ids := map[string]string{}
+		for _, p := range []fiber.Map{
+			{"name": "Es Teh", "price": 8000, "description": "Dengan sedikit kopi dan susu"},
+			{"name": "Kopi Susu", "price": 20000, "description": "Gula aren"},
+			{"name": "Roti Kopi", "price": 12000, "description": "Isi krim SUSU"},
+			{"name": "Teh Melati", "price": 7000, "description": "Teh hijau wangi"},
+			{"name": "Susu Kopi Lama", "price": 5000, "description": "Sudah dihapus"},
+		} {
+			r := env.do(t, http.MethodPost, "/api/products", token, p)
+			ids[p["name"].(string)] = r.data()["id"].(string)
+		}
+		expect(t, env.doMatch(t, http.MethodDelete, "/api/products/"+ids["Susu Kopi Lama"], token, "*", nil), http.StatusOK, "")
+
+		get := func(query string) response {
+			return env.do(t, http.MethodGet, "/api/products?"+query, token, nil)
+		}
+
+		// Tanpa sort: urut relevansi
+		r := get("q=kopi%20susu")
+		expectNames(t, r, "name", "Kopi Susu", "Roti Kopi", "Es Teh")
+		if r.Body["count"] != float64(3) {
+			t.Fatalf("count = %v, want 3", r.Body["count"])
+		}
+		if rank := r.list()[0].(map[string]any)["rank"]; rank != float64(2) {
+			t.Fatalf("rank = %v, want 2", rank)
+		}
+
+		// sort eksplisit menggantikan relevansi
+		expectNames(t, get("q=kopi%20susu&sort=price"), "name", "Es Teh", "Roti Kopi", "Kopi Susu")
+		expectNames(t, get("q=TEH&sort=price"), "name", "Teh Melati", "Es Teh")
+		expectNames(t, get("q=100%25"), "name")
+
+		// Cursor mengikuti urutan relevansi
+		r = get("q=kopi%20susu&limit=2")
+		expectNames(t, r, "name", "Kopi Susu", "Roti Kopi")
+		cursor := r.Body["next_cursor"].(string)
+		expectNames(t, get("q=kopi%20susu&limit=2&cursor="+cursor), "name", "Es Teh")
+		expect(t, get("q=kopi%20susu&sort=price&limit=2&cursor="+cursor), http.StatusBadRequest, string(utils.ErrInvalidCursor.Code))
+
+		// Digabung dengan filter lain
+		expectNames(t, get("q=kopi&max_price=15000"), "name", "Roti Kopi", "Es Teh")
+	})
+}
+
+func TestCategoryRoutes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		// Hanya admin yang boleh mengubah kategori
+		expect(t, env.do(t, http.MethodPost, "/api/categories", token, fiber.Map{"name": "Minuman"}), http.StatusForbidden, string(utils.ErrForbidden.Code))
+		env.promote(t, "budi@example.com")
+
+		create := func(body fiber.Map) map[string]any {
+			t.Helper()
+			r := env.do(t, http.MethodPost, "/api/categories", token, body)
+			expect(t, r, http.StatusOK, string(utils.MsgCategoryCreated))
+			return r.data()
+		}
+		drinks := create(fiber.Map{"name": "Minuman & Es", "sort_order": 1})
+		if drinks["slug"] != "minuman-es" {
+			t.Fatalf("slug = %v, want minuman-es", drinks["slug"])
+		}
+		food := create(fiber.Map{"name": "Makanan", "sort_order": 0})
+		coffee := create(fiber.Map{"name": "Kopi", "parent_id": drinks["id"]})
+		tea := create(fiber.Map{"name": "Teh", "parent_id": drinks["id"], "sort_order": -1})
+		arabica := create(fiber.Map{"name": "Arabika", "slug": "kopi-arabika", "parent_id": coffee["id"]})
+
+		for code, body := range map[string]fiber.Map{
+			string(utils.ErrCategoryNameRequired.Code):  {"name": " "},
+			string(utils.ErrInvalidSlug.Code):           {"name": "Roti", "slug": "Roti Tawar"},
+			string(utils.ErrSlugInUse.Code):             {"name": "Kopi"},
+			string(utils.ErrInvalidParentCategory.Code): {"name": "Roti", "parent_id": uuid.NewString()},
+		} {
+			r := env.do(t, http.MethodPost, "/api/categories", token, body)
+			if r.code() != code {
+				t.Fatalf("POST %v: code = %s, want %s", body, r.code(), code)
+			}
+		}
+
+		// Tree: urut sort_order lalu nama di setiap level
+		r := env.do(t, http.MethodGet, "/api/categories/tree", token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgCategoryTreeRetrieved))
+		roots := r.Body["data"].([]any)
+		if len(roots) != 2 || roots[0].(map[string]any)["name"] != "Makanan" || roots[0].(map[string]any)["children"] != nil {
+			t.Fatalf("roots = %v", roots)
+		}
+		children := roots[1].(map[string]any)["children"].([]any)
+		if len(children) != 2 || children[0].(map[string]any)["name"] != "Teh" || children[1].(map[string]any)["name"] != "Kopi" {
+			t.Fatalf("children = %v", children)
+		}
+		if grandchildren := children[1].(map[string]any)["children"].([]any); len(grandchildren) != 1 || grandchildren[0].(map[string]any)["name"] != "Arabika" {
+			t.Fatalf("grandchildren = %v", grandchildren)
+		}
+
+		if got := env.do(t, http.MethodGet, "/api/categories", token, nil).Body["data"].([]any); len(got) != 5 {
+			t.Fatalf("len(categories) = %d, want 5", len(got))
+		}
+		expect(t, env.do(t, http.MethodGet, "/api/categories/kopi-arabika", token, nil), http.StatusOK, string(utils.MsgCategoryRetrieved))
+		expect(t, env.do(t, http.MethodGet, "/api/categories/"+tea["id"].(string), token, nil), http.StatusOK, string(utils.MsgCategoryRetrieved))
+		expect(t, env.do(t, http.MethodGet, "/api/categories/tidak-ada", token, nil), http.StatusNotFound, string(utils.ErrCategoryNotFound.Code))
+
+		// Parent tidak boleh diri sendiri atau turunannya
+		path := "/api/categories/" + drinks["id"].(string)
+		expect(t, env.do(t, http.MethodPut, path, token, fiber.Map{"parent_id": drinks["id"]}), http.StatusBadRequest, string(utils.ErrInvalidParentCategory.Code))
+		expect(t, env.do(t, http.MethodPut, path, token, fiber.Map{"parent_id": arabica["id"]}), http.StatusBadRequest, string(utils.ErrInvalidParentCategory.Code))
+		r = env.do(t, http.MethodPut, path, token, fiber.Map{"parent_id": food["id"], "name": "Minuman"})
+		expect(t, r, http.StatusOK, string(utils.MsgCategoryUpdated))
+		if r.data()["parent_id"] != food["id"] || r.data()["slug"] != "minuman-es" {
+			t.Fatalf("updated = %v", r.data())
+		}
+		r = env.do(t, http.MethodPut, path, token, fiber.Map{"parent_id": ""})
+		if r.data()["parent_id"] != nil {
+			t.Fatalf("parent_id = %v, want null", r.data()["parent_id"])
+		}
+		expect(t, env.do(t, http.MethodPut, "/api/categories/kopi-arabika", token, fiber.Map{"slug": "teh"}), http.StatusConflict, string(utils.ErrSlugInUse.Code))
+
+		// Kategori yang masih punya anak tidak bisa dihapus
+		expect(t, env.do(t, http.MethodDelete, "/api/categories/"+coffee["id"].(string), token, nil), http.StatusConflict, string(utils.ErrCategoryHasChildren.Code))
+		expect(t, env.do(t, http.MethodDelete, "/api/categories/kopi-arabika", token, nil), http.StatusOK, string(utils.MsgCategoryDeleted))
+		expect(t, env.do(t, http.MethodDelete, "/api/categories/"+coffee["id"].(string), token, nil), http.StatusOK, string(utils.MsgCategoryDeleted))
+	})
+}
+
+func TestProductCategories(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+		env.promote(t, "budi@example.com")
+
+		category := func(name string, parent any) string {
+			t.Helper()
+			r := env.do(t, http.MethodPost, "/api/categories", token, fiber.Map{"name": name, "parent_id": parent})
+			expect(t, r, http.StatusOK, string(utils.MsgCategoryCreated))
+			return r.data()["id"].(string)
+		}
+		drinks := category("Minuman", nil)
+		coffee := category("Kopi", drinks)
+		arabica := category("Arabika", coffee)
+		food := category("Makanan", nil)
+
+		product := func(name string, categories ...string) string {
+			t.Helper()
+			r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": name, "price": 10000, "category_ids": categories})
+			expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+			if got := len(r.data()["categories"].([]any)); got != len(categories) {
+				t.Fatalf("%s: %d kategori, want %d", name, got, len(categories))
+			}
+			return r.data()["id"].(string)
+		}
+		product("Gayo", arabica)
+		kopiSusu := product("Kopi Susu", coffee, food)
+		product("Es Jeruk", drinks)
+		product("Roti", food)
+		product("Air Putih")
+
+		r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "X", "price": 1, "category_ids": []string{uuid.NewString()}})
+		expect(t, r, http.StatusBadRequest, string(utils.ErrUnknownCategory.Code))
+
+		get := func(query string) response {
+			return env.do(t, http.MethodGet, "/api/products?sort=name&"+query, token, nil)
+		}
+
+		// category= mencakup semua turunan, bisa memakai ID atau slug
+		expectNames(t, get("category=minuman"), "name", "Es Jeruk", "Gayo", "Kopi Susu")
+		expectNames(t, get("category="+coffee), "name", "Gayo", "Kopi Susu")
+		expectNames(t, get("category=arabika"), "name", "Gayo")
+		expectNames(t, get("category=makanan&max_price=20000"), "name", "Kopi Susu", "Roti")
+		expectNames(t, get("category=minuman&q=kopi"), "name", "Kopi Susu")
+		expect(t, get("category=tidak-ada"), http.StatusBadRequest, string(utils.ErrInvalidFilter.Code))
+
+		// Kategori ikut di list dan GET, urut sort_order lalu nama
+		r = env.do(t, http.MethodGet, "/api/products/"+kopiSusu, token, nil)
+		categories := r.data()["categories"].([]any)
+		if len(categories) != 2 || categories[0].(map[string]any)["name"] != "Kopi" || categories[1].(map[string]any)["name"] != "Makanan" {
+			t.Fatalf("categories = %v", categories)
+		}
+
+		// Update tanpa category_ids tidak mengubah kategori, [] menghapus semuanya
+		path := "/api/products/" + kopiSusu
+		expect(t, env.doMatch(t, http.MethodPatch, path, token, "*", fiber.Map{"price": 22000}), http.StatusOK, string(utils.MsgProductUpdated))
+		expectNames(t, get("category=makanan"), "name", "Kopi Susu", "Roti")
+		r = env.doMatch(t, http.MethodPatch, path, token, "*", fiber.Map{"category_ids": []string{drinks}})
+		if categories := r.data()["categories"].([]any); len(categories) != 1 {
+			t.Fatalf("categories = %v", categories)
+		}
+		expectNames(t, get("category=makanan"), "name", "Roti")
+		expectNames(t, get("category=minuman"), "name", "Es Jeruk", "Gayo", "Kopi Susu")
+		env.doMatch(t, http.MethodPatch, path, token, "*", fiber.Map{"category_ids": []string{}})
+		expectNames(t, get("category=minuman"), "name", "Es Jeruk", "Gayo")
+
+		// Rename dan hapus kategori terlihat dari produk
+		env.do(t, http.MethodPut, "/api/categories/makanan", token, fiber.Map{"name": "Makanan Ringan"})
+		r = get("category=makanan")
+		if name := r.list()[0].(map[string]any)["categories"].([]any)[0].(map[string]any)["name"]; name != "Makanan Ringan" {
+			t.Fatalf("category name = %v", name)
+		}
+		expect(t, env.do(t, http.MethodDelete, "/api/categories/makanan", token, nil), http.StatusOK, string(utils.MsgCategoryDeleted))
+		if r := get("q=roti"); r.list()[0].(map[string]any)["categories"] != nil {
+			t.Fatalf("categories = %v", r.list()[0])
+		}
+	})
+}
+
+func TestInventory(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Kopi", "price": 25000, "low_stock_threshold": 3})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		if r.data()["stock"] != float64(0) || r.data()["low_stock_threshold"] != float64(3) {
+			t.Fatalf("product = %v", r.data())
+		}
+		path := "/api/products/" + r.data()["id"].(string) + "/movements"
+
+		move := func(body fiber.Map) response {
+			t.Helper()
+			return env.do(t, http.MethodPost, path, token, body)
+		}
+		expectStock := func(r response, stock, reserved float64) {
+			t.Helper()
+			expect(t, r, http.StatusOK, string(utils.MsgStockUpdated))
+			product := r.data()["product"].(map[string]any)
+			if product["stock"] != stock || product["reserved"] != reserved || product["available"] != stock-reserved {
+				t.Fatalf("stock/reserved/available = %v/%v/%v, want %v/%v", product["stock"], product["reserved"], product["available"], stock, reserved)
+			}
+		}
+
+		expectStock(move(fiber.Map{"type": "receive", "quantity": 10, "reason": "PO-001"}), 10, 0)
+		expectStock(move(fiber.Map{"type": "reserve", "quantity": 4}), 10, 4)
+		expectStock(move(fiber.Map{"type": "release", "quantity": 1}), 10, 3)
+		expectStock(move(fiber.Map{"type": "sell", "quantity": 2}), 8, 1)
+		expectStock(move(fiber.Map{"type": "adjust", "quantity": -3, "reason": "rusak"}), 5, 1)
+		// Produk baru dengan stok 0 dimulai di bawah threshold; receive pertama memulihkannya
+		if got := env.events.take(); !slices.Equal(got, []string{events.ProductStockRestored}) {
+			t.Fatalf("events = %v", got)
+		}
+
+		// Stok tidak boleh negatif dan reserved tidak boleh melebihi stok
+		r = move(fiber.Map{"type": "reserve", "quantity": 5})
+		expect(t, r, http.StatusUnprocessableEntity, string(utils.ErrInsufficientStock.Code))
+		if r.data()["available"] != float64(4) {
+			t.Fatalf("available = %v, want 4", r.data()["available"])
+		}
+		expect(t, move(fiber.Map{"type": "release", "quantity": 2}), http.StatusUnprocessableEntity, string(utils.ErrInsufficientStock.Code))
+		expect(t, move(fiber.Map{"type": "sell", "quantity": 2}), http.StatusUnprocessableEntity, string(utils.ErrInsufficientStock.Code))
+		expect(t, move(fiber.Map{"type": "adjust", "quantity": -5, "reason": "hilang"}), http.StatusUnprocessableEntity, string(utils.ErrInsufficientStock.Code))
+
+		for code, body := range map[*utils.AppError]fiber.Map{
+			utils.ErrInvalidMovementType:    {"type": "steal", "quantity": 1},
+			utils.ErrInvalidQuantity:        {"type": "receive", "quantity": -1},
+			utils.ErrMovementReasonRequired: {"type": "adjust", "quantity": 1, "reason": " "},
+		} {
+			expect(t, move(body), http.StatusBadRequest, string(code.Code))
+		}
+		expect(t, env.do(t, http.MethodPost, "/api/products/"+uuid.NewString()+"/movements", token, fiber.Map{"type": "receive", "quantity": 1}), http.StatusNotFound, string(utils.ErrProductNotFound.Code))
+
+		// Event hanya saat melewati threshold (available 4 -> 3 -> 2 -> 5)
+		expectStock(move(fiber.Map{"type": "reserve", "quantity": 1}), 5, 2)
+		expectStock(move(fiber.Map{"type": "reserve", "quantity": 1}), 5, 3)
+		if got := env.events.take(); !slices.Equal(got, []string{events.ProductStockLow}) {
+			t.Fatalf("events = %v", got)
+		}
+		expectStock(move(fiber.Map{"type": "receive", "quantity": 3}), 8, 3)
+		if got := env.events.take(); !slices.Equal(got, []string{events.ProductStockRestored}) {
+			t.Fatalf("events = %v", got)
+		}
+
+		// Mengubah threshold juga bisa melewati batas; null menghapus threshold
+		productPath := strings.TrimSuffix(path, "/movements")
+		r = env.doMatch(t, http.MethodPatch, productPath, token, "*", fiber.Map{"low_stock_threshold": 5})
+		if r.data()["low_stock_threshold"] != float64(5) || r.data()["stock"] != float64(8) {
+			t.Fatalf("product = %v", r.data())
+		}
+		expect(t, env.doMatch(t, http.MethodPatch, productPath, token, "*", fiber.Map{"low_stock_threshold": -1}), http.StatusBadRequest, string(utils.ErrInvalidStockThreshold.Code))
+		r = env.doMatch(t, http.MethodPatch, productPath, token, "*", fiber.Map{"low_stock_threshold": nil})
+		if r.data()["low_stock_threshold"] != nil {
+			t.Fatalf("low_stock_threshold = %v, want null", r.data()["low_stock_threshold"])
+		}
+		if got := env.events.take(); !slices.Equal(got, []string{events.ProductStockLow, events.ProductStockRestored}) {
+			t.Fatalf("events = %v", got)
+		}
+
+		// Riwayat, terbaru lebih dulu
+		r = env.do(t, http.MethodGet, path+"?limit=3", token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgMovementsRetrieved))
+		if r.Body["count"] != float64(8) {
+			t.Fatalf("count = %v, want 8", r.Body["count"])
+		}
+		latest := r.list()[0].(map[string]any)
+		if latest["type"] != "receive" || latest["quantity"] != float64(3) || latest["stock_after"] != float64(8) || latest["reserved_after"] != float64(3) || latest["user_id"] == nil {
+			t.Fatalf("latest = %v", latest)
+		}
+		r = env.do(t, http.MethodGet, path+"?sort=created_at&limit=1", token, nil)
+		if first := r.list()[0].(map[string]any); first["type"] != "receive" || first["reason"] != "PO-001" {
+			t.Fatalf("first = %v", first)
+		}
+	})
+}
+
+// Reservasi paralel tidak boleh melebihi stok
+func TestConcurrentReservations(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+		r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Kopi", "price": 25000})
+		path := "/api/products/" + r.data()["id"].(string)
+		expect(t, env.do(t, http.MethodPost, path+"/movements", token, fiber.Map{"type": "receive", "quantity": 10}), http.StatusOK, string(utils.MsgStockUpdated))
+
+		var wg sync.WaitGroup
+		statuses := make(chan int, 25)
+		for range 25 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				statuses <- env.do(t, http.MethodPost, path+"/movements", token, fiber.Map{"type": "reserve", "quantity": 1}).Status
+			}()
+		}
+		// Update produk di tengah reservasi tidak boleh menimpa stok
+		env.doMatch(t, http.MethodPatch, path, token, "*", fiber.Map{"price": 26000})
+		wg.Wait()
+		close(statuses)
+
+		count := map[int]int{}
+		for status := range statuses {
+			count[status]++
+		}
+		if count[http.StatusOK] != 10 || count[http.StatusUnprocessableEntity] != 15 {
+			t.Fatalf("statuses = %v", count)
+		}
+
+		r = env.do(t, http.MethodGet, path, token, nil)
+		if r.data()["stock"] != float64(10) || r.data()["reserved"] != float64(10) || r.data()["price"] != float64(26000) {
+			t.Fatalf("product = %v", r.data())
+		}
+	})
+}
+
+func TestBankSortAndFilter(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		for i, name := range []string{"BCA", "BNI", "Mandiri"} {
+			r := env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": name, "account_no": name + "-001"})
+			env.do(t, http.MethodPut, "/api/bank/"+r.data()["id"].(string)+"/add-money", token, fiber.Map{"amount": (i + 1) * 1000})
+		}
+
+		expectNames(t, env.do(t, http.MethodGet, "/api/banks?sort=-nominal", token, nil), "bank_name", "Mandiri", "BNI", "BCA")
+		expectNames(t, env.do(t, http.MethodGet, "/api/banks?min_nominal=2000&sort=bank_name", token, nil), "bank_name", "BNI", "Mandiri")
+		expect(t, env.do(t, http.MethodGet, "/api/banks?sort=account_no", token, nil), http.StatusBadRequest, string(utils.ErrInvalidSort.Code))
+	})
+}
+
+func TestProductVariants(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{
+			"name":  "Kaos",
+			"price": 100000,
+			"options": []fiber.Map{
+				{"name": " size ", "values": []string{"S", "M", "L"}},
+				{"name": "color", "values": []string{"hitam", "putih"}},
+			},
+		})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		if options := r.data()["options"].([]any); len(options) != 2 || options[0].(map[string]any)["name"] != "size" {
+			t.Fatalf("options = %v", r.data()["options"])
+		}
+		productPath := "/api/products/" + r.data()["id"].(string)
+		path := productPath + "/variants"
+
+		// Produk tanpa varian: price_range = harga produk
+		r = env.do(t, http.MethodGet, productPath, token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductRetrieved))
+		if got := r.data()["price_range"].(map[string]any); got["min"] != float64(100000) || got["max"] != float64(100000) || len(r.data()["variants"].([]any)) != 0 {
+			t.Fatalf("product = %v", r.data())
+		}
+
+		r = env.doMatch(t, http.MethodPost, path, token, "*", fiber.Map{"sku": " kaos-s-hitam ", "stock": 5, "attributes": fiber.Map{"size": "S", "color": "hitam"}})
+		expect(t, r, http.StatusOK, string(utils.MsgVariantCreated))
+		if r.data()["sku"] != "KAOS-S-HITAM" || r.data()["price"] != nil || r.data()["effective_price"] != float64(100000) {
+			t.Fatalf("variant = %v", r.data())
+		}
+		small := r.data()["id"].(string)
+
+		r = env.doMatch(t, http.MethodPost, path, token, "*", fiber.Map{"sku": "KAOS-L-PUTIH", "price": 120000, "attributes": fiber.Map{"size": "L", "color": "putih"}})
+		expect(t, r, http.StatusOK, string(utils.MsgVariantCreated))
+		large := r.data()["id"].(string)
+
+		for code, body := range map[*utils.AppError]fiber.Map{
+			utils.ErrInvalidSKU:               {"sku": "kaos s", "attributes": fiber.Map{"size": "M", "color": "hitam"}},
+			utils.ErrInvalidVariant:           {"sku": "KAOS-M", "price": 0.0, "attributes": fiber.Map{"size": "M", "color": "hitam"}},
+			utils.ErrInvalidVariantAttributes: {"sku": "KAOS-M", "attributes": fiber.Map{"size": "XL", "color": "hitam"}},
+		} {
+			expect(t, env.doMatch(t, http.MethodPost, path, token, "*", body), http.StatusBadRequest, string(code.Code))
+		}
+		expect(t, env.doMatch(t, http.MethodPost, path, token, "*", fiber.Map{"sku": "KAOS-M", "attributes": fiber.Map{"size": "M"}}), http.StatusBadRequest, string(utils.ErrInvalidVariantAttributes.Code))
+		expect(t, env.doMatch(t, http.MethodPost, path, token, "*", fiber.Map{"sku": "KAOS-S-HITAM", "attributes": fiber.Map{"size": "M", "color": "hitam"}}), http.StatusConflict, string(utils.ErrSKUInUse.Code))
+		expect(t, env.doMatch(t, http.MethodPost, path, token, "*", fiber.Map{"sku": "KAOS-S-HITAM-2", "attributes": fiber.Map{"size": "S", "color": "hitam"}}), http.StatusConflict, string(utils.ErrDuplicateVariant.Code))
+
+		// GET produk: varian dan rentang harga (override atau harga produk)
+		r = env.do(t, http.MethodGet, productPath, token, nil)
+		if got := r.data()["price_range"].(map[string]any); got["min"] != float64(100000) || got["max"] != float64(120000) {
+			t.Fatalf("price_range = %v", got)
+		}
+		if variants := r.data()["variants"].([]any); len(variants) != 2 {
+			t.Fatalf("variants = %v", variants)
+		}
+
+		// Update: null menghapus override harga
+		r = env.doMatch(t, http.MethodPut, path+"/"+large, token, "*", fiber.Map{"price": nil, "stock": 7})
+		expect(t, r, http.StatusOK, string(utils.MsgVariantUpdated))
+		if r.data()["price"] != nil || r.data()["effective_price"] != float64(100000) || r.data()["stock"] != float64(7) || r.data()["sku"] != "KAOS-L-PUTIH" {
+			t.Fatalf("variant = %v", r.data())
+		}
+		expect(t, env.doMatch(t, http.MethodPut, path+"/"+large, token, "*", fiber.Map{"sku": "KAOS-S-HITAM"}), http.StatusConflict, string(utils.ErrSKUInUse.Code))
+		expect(t, env.doMatch(t, http.MethodPut, path+"/"+large, token, "*", fiber.Map{"stock": -1}), http.StatusBadRequest, string(utils.ErrInvalidVariant.Code))
+		expect(t, env.doMatch(t, http.MethodPut, path+"/"+large, token, "*", fiber.Map{"attributes": fiber.Map{"size": "S", "color": "hitam"}}), http.StatusConflict, string(utils.ErrDuplicateVariant.Code))
+
+		r = env.do(t, http.MethodGet, path, token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgVariantsRetrieved))
+		if len(r.list()) != 2 {
+			t.Fatalf("variants = %v", r.list())
+		}
+
+		// Option tidak boleh diubah sampai varian yang ada tidak valid lagi
+		expect(t, env.doMatch(t, http.MethodPatch, productPath, token, "*", fiber.Map{"options": []fiber.Map{{"name": "size", "values": []string{"S", "M"}}}}), http.StatusConflict, string(utils.ErrOptionsInUse.Code))
+		expect(t, env.doMatch(t, http.MethodPatch, productPath, token, "*", fiber.Map{"options": []fiber.Map{{"name": "size", "values": []string{"S", "S"}}}}), http.StatusBadRequest, string(utils.ErrInvalidProductOptions.Code))
+		r = env.doMatch(t, http.MethodPatch, productPath, token, "*", fiber.Map{"options": []fiber.Map{
+			{"name": "size", "values": []string{"S", "M", "L", "XL"}},
+			{"name": "color", "values": []string{"hitam", "putih"}},
+		}})
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+
+		// Varian milik produk lain dianggap tidak ada
+		r = env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Topi", "price": 50000})
+		otherPath := "/api/products/" + r.data()["id"].(string) + "/variants/"
+		expect(t, env.do(t, http.MethodGet, otherPath+small, token, nil), http.StatusNotFound, string(utils.ErrVariantNotFound.Code))
+		expect(t, env.do(t, http.MethodGet, path+"/"+uuid.NewString(), token, nil), http.StatusNotFound, string(utils.ErrVariantNotFound.Code))
+
+		expect(t, env.doMatch(t, http.MethodDelete, path+"/"+small, token, "*", nil), http.StatusOK, string(utils.MsgVariantDeleted))
+		expect(t, env.do(t, http.MethodGet, path+"/"+small, token, nil), http.StatusNotFound, string(utils.ErrVariantNotFound.Code))
+		// SKU varian yang dihapus boleh dipakai lagi
+		expect(t, env.doMatch(t, http.MethodPost, path, token, "*", fiber.Map{"sku": "KAOS-S-HITAM", "attributes": fiber.Map{"size": "S", "color": "hitam"}}), http.StatusOK, string(utils.MsgVariantCreated))
+	})
+}
+
+func encodeImage(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProductImages(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+		r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Kopi", "price": 25000, "status": "published"})
+		productPath := "/api/products/" + r.data()["id"].(string)
+		path := productPath + "/images"
+
+		r = env.upload(t, path, token, "*", encodeImage(t, "png", 1000, 500))
+		expect(t, r, http.StatusOK, string(utils.MsgImageUploaded))
+		if r.data()["content_type"] != "image/png" || r.data()["width"] != float64(1000) || r.data()["position"] != float64(0) {
+			t.Fatalf("image = %v", r.data())
+		}
+		first := r.data()["id"].(string)
+		thumbURL := r.data()["urls"].(map[string]any)["thumb"].(string)
+
+		r = env.upload(t, path, token, "*", encodeImage(t, "jpeg", 300, 300))
+		expect(t, r, http.StatusOK, string(utils.MsgImageUploaded))
+		if r.data()["content_type"] != "image/jpeg" || r.data()["position"] != float64(1) {
+			t.Fatalf("image = %v", r.data())
+		}
+		second := r.data()["id"].(string)
+
+		// Format dari isi file, bukan nama file atau Content-Type
+		expect(t, env.upload(t, path, token, "*", []byte("bukan gambar")), http.StatusUnsupportedMediaType, string(utils.ErrUnsupportedImage.Code))
+		expect(t, env.upload(t, path, token, "*", bytes.Repeat([]byte{0xFF}, 70*1024)), http.StatusRequestEntityTooLarge, string(utils.ErrImageTooLarge.Code))
+		expect(t, env.doMatch(t, http.MethodPost, path, token, "*", fiber.Map{}), http.StatusBadRequest, string(utils.ErrImageRequired.Code))
+
+		// File dilayani tanpa login dengan header cache
+		resp, err := env.app.Test(httptest.NewRequest(http.MethodGet, thumbURL, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		thumb, err := png.DecodeConfig(resp.Body)
+		resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK || thumb.Width != 200 || thumb.Height != 100 {
+			t.Fatalf("thumb: status %d, %dx%d, err %v", resp.StatusCode, thumb.Width, thumb.Height, err)
+		}
+		if resp.Header.Get(fiber.HeaderContentType) != "image/png" || !strings.Contains(resp.Header.Get(fiber.HeaderCacheControl), "immutable") {
+			t.Fatalf("headers = %v", resp.Header)
+		}
+		req := httptest.NewRequest(http.MethodGet, thumbURL, nil)
+		req.Header.Set(fiber.HeaderIfNoneMatch, resp.Header.Get(fiber.HeaderETag))
+		if resp, err := env.app.Test(req, -1); err != nil || resp.StatusCode != http.StatusNotModified {
+			t.Fatalf("If-None-Match: status %v, err %v", resp.StatusCode, err)
+		}
+		expect(t, env.do(t, http.MethodGet, "/images/"+first+"/huge", "", nil), http.StatusNotFound, string(utils.ErrImageNotFound.Code))
+
+		imageIDs := func(r response) []string {
+			t.Helper()
+			var ids []string
+			for _, item := range r.Body["data"].([]any) {
+				ids = append(ids, item.(map[string]any)["id"].(string))
+			}
+			return ids
+		}
+
+		// Urutan
+		r = env.doMatch(t, http.MethodPut, path, token, "*", fiber.Map{"image_ids": []string{second, first}})
+		expect(t, r, http.StatusOK, string(utils.MsgImagesReordered))
+		if got := imageIDs(r); !slices.Equal(got, []string{second, first}) {
+			t.Fatalf("order = %v", got)
+		}
+		expect(t, env.doMatch(t, http.MethodPut, path, token, "*", fiber.Map{"image_ids": []string{second}}), http.StatusBadRequest, string(utils.ErrInvalidImageOrder.Code))
+		expect(t, env.doMatch(t, http.MethodPut, path, token, "*", fiber.Map{"image_ids": []string{second, second}}), http.StatusBadRequest, string(utils.ErrInvalidImageOrder.Code))
+
+		r = env.do(t, http.MethodGet, productPath, token, nil)
+		if images := r.data()["images"].([]any); len(images) != 2 || images[0].(map[string]any)["id"] != second {
+			t.Fatalf("images = %v", images)
+		}
+
+		// Hapus gambar beserta file-nya
+		expect(t, env.doMatch(t, http.MethodDelete, path+"/"+first, token, "*", nil), http.StatusOK, string(utils.MsgImageDeleted))
+		expect(t, env.do(t, http.MethodGet, thumbURL, "", nil), http.StatusNotFound, string(utils.ErrImageNotFound.Code))
+		expect(t, env.doMatch(t, http.MethodDelete, path+"/"+first, token, "*", nil), http.StatusNotFound, string(utils.ErrImageNotFound.Code))
+		if got := imageIDs(env.do(t, http.MethodGet, path, token, nil)); !slices.Equal(got, []string{second}) {
+			t.Fatalf("images = %v", got)
+		}
+
+		// Gambar produk draft tidak dilayani publik, hanya lewat /api untuk pemilik atau admin
+		expect(t, env.doMatch(t, http.MethodPatch, productPath, token, "*", fiber.Map{"status": "draft"}), http.StatusOK, string(utils.MsgProductUpdated))
+		expect(t, env.do(t, http.MethodGet, "/images/"+second+"/original", "", nil), http.StatusNotFound, string(utils.ErrImageNotFound.Code))
+		req = httptest.NewRequest(http.MethodGet, "/api/images/"+second+"/original", nil)
+		req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		resp, err = env.app.Test(req, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get(fiber.HeaderCacheControl) != "private, no-cache" {
+			t.Fatalf("owner: status %d, headers %v", resp.StatusCode, resp.Header)
+		}
+		other := env.login(t, "siti@example.com")
+		expect(t, env.do(t, http.MethodGet, "/api/images/"+second+"/original", other, nil), http.StatusForbidden, string(utils.ErrNotProductOwner.Code))
+
+		// Gambar produk yang sudah dihapus tidak dilayani lagi
+		expect(t, env.doMatch(t, http.MethodDelete, productPath, token, "*", nil), http.StatusOK, string(utils.MsgProductDeleted))
+		expect(t, env.do(t, http.MethodGet, "/images/"+second+"/original", "", nil), http.StatusNotFound, string(utils.ErrImageNotFound.Code))
+		expect(t, env.do(t, http.MethodGet, "/api/images/"+second+"/original", token, nil), http.StatusNotFound, string(utils.ErrImageNotFound.Code))
+	})
+}
+
+// importFile mengirim file katalog sebagai body request
+func (e *testEnv) importFile(t *testing.T, query, token, contentType, file string) response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/products/import"+query, strings.NewReader(file))
+	req.Header.Set(fiber.HeaderContentType, contentType)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	return e.send(t, req)
+}
+
+func (e *testEnv) export(t *testing.T, format, token string) (*http.Response, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/products/export?format="+format, nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	resp, err := e.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(resp.Body)
+	return resp, string(raw)
+}
+
+func TestProductImportExport(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+		env.promote(t, "budi@example.com")
+		r := env.do(t, http.MethodPost, "/api/categories", token, fiber.Map{"name": "Elektronik"})
+		expect(t, r, http.StatusOK, string(utils.MsgCategoryCreated))
+
+		file := "\ufeffsku;name;price;categories;low_stock_threshold\n" +
+			"lap-01;Laptop;15000000;elektronik;2\n" +
+			"MOU-01;Mouse;150000;;\n" +
+			"lap-01;Laptop lagi;1;;\n" +
+			"KBD-01;;100000;;\n" +
+			"SPK-01;Speaker;murah;;\n" +
+			"HP-01;HP;2000000;gadget;\n"
+
+		report := func(r response, created, updated, failed int, codes ...string) {
+			t.Helper()
+			data := r.data()
+			if data["created"] != float64(created) || data["updated"] != float64(updated) || data["failed"] != float64(failed) {
+				t.Fatalf("report = %v", data)
+			}
+			var got []string
+			for _, row := range data["errors"].([]any) {
+				got = append(got, row.(map[string]any)["code"].(string))
+			}
+			if !slices.Equal(got, codes) {
+				t.Fatalf("errors = %v, want %v", data["errors"], codes)
+			}
+		}
+		rowErrors := []string{string(utils.ErrDuplicateRow.Code), string(utils.ErrProductNameAndPriceRequired.Code), string(utils.ErrInvalidValue.Code), string(utils.ErrUnknownCategory.Code)}
+
+		// Dry run tidak menyimpan apa pun
+		r = env.importFile(t, "?dry_run=true", token, "text/csv", file)
+		expect(t, r, http.StatusOK, string(utils.MsgImportValidated))
+		report(r, 2, 0, 4, rowErrors...)
+		if first := r.data()["errors"].([]any)[0].(map[string]any); first["row"] != float64(4) || first["sku"] != "lap-01" {
+			t.Fatalf("error row = %v", first)
+		}
+		r = env.do(t, http.MethodGet, "/api/products", token, nil)
+		if len(r.list()) != 0 {
+			t.Fatalf("dry run menyimpan produk: %v", r.list())
+		}
+
+		r = env.importFile(t, "", token, "text/csv", file)
+		expect(t, r, http.StatusOK, string(utils.MsgImportCompleted))
+		report(r, 2, 0, 4, rowErrors...)
+
+		r = env.do(t, http.MethodGet, "/api/products?sort=name", token, nil)
+		expectNames(t, r, "name", "Laptop", "Mouse")
+		laptop := r.list()[0].(map[string]any)
+		if laptop["sku"] != "LAP-01" || laptop["low_stock_threshold"] != float64(2) || len(laptop["categories"].([]any)) != 1 {
+			t.Fatalf("laptop = %v", laptop)
+		}
+
+		// Upsert: field yang tidak ada di baris tidak diubah, null menghapus threshold.
+		// Harga 0 (produk gratis) boleh seperti di API, harga negatif tidak.
+		r = env.importFile(t, "", token, "application/x-ndjson",
+			`{"sku":"LAP-01","price":14000000,"low_stock_threshold":null}`+"\n\n"+
+				`{"sku":"CAM-01","name":"Kamera","price":5000000,"categories":["elektronik"]}`+"\n"+
+				`{"sku":"STK-01","name":"Stiker","price":0}`+"\n"+
+				`{"sku":"NEG-01","name":"Minus","price":-1}`+"\n")
+		expect(t, r, http.StatusOK, string(utils.MsgImportCompleted))
+		report(r, 2, 1, 1, string(utils.ErrInvalidPrice.Code))
+
+		r = env.do(t, http.MethodGet, "/api/products/"+laptop["id"].(string), token, nil)
+		if r.data()["name"] != "Laptop" || r.data()["price"] != float64(14000000) || r.data()["low_stock_threshold"] != nil {
+			t.Fatalf("laptop = %v", r.data())
+		}
+
+		// SKU lewat API produk memakai aturan yang sama
+		r = env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Laptop 2", "price": 1, "sku": "lap-01"})
+		expect(t, r, http.StatusConflict, string(utils.ErrSKUInUse.Code))
+
+		r = env.importFile(t, "", token, "text/csv", "name,price\nLaptop,1\n")
+		expect(t, r, http.StatusBadRequest, string(utils.ErrInvalidImportFile.Code))
+		r = env.importFile(t, "", token, "application/pdf", "%PDF")
+		expect(t, r, http.StatusUnsupportedMediaType, string(utils.ErrUnsupportedImportFormat.Code))
+
+		resp, body := env.export(t, "csv", token)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "text/csv; charset=utf-8" {
+			t.Fatalf("export = %d %v", resp.StatusCode, resp.Header)
+		}
+		lines := strings.Split(strings.TrimSpace(body), "\n")
+		if len(lines) != 5 || lines[0] != "sku,name,description,price,categories,low_stock_threshold,stock,reserved" {
+			t.Fatalf("export csv = %q", body)
+		}
+
+		// Hasil export (termasuk produk gratis) bisa di-import ulang tanpa perubahan
+		resp, body = env.export(t, "ndjson", token)
+		if resp.StatusCode != http.StatusOK || strings.Count(body, "\n") != 4 {
+			t.Fatalf("export ndjson = %d %q", resp.StatusCode, body)
+		}
+		r = env.importFile(t, "", token, "application/x-ndjson", body)
+		expect(t, r, http.StatusOK, string(utils.MsgImportCompleted))
+		report(r, 0, 4, 0)
+
+		r = env.do(t, http.MethodGet, "/api/products/export?format=xml", token, nil)
+		expect(t, r, http.StatusBadRequest, string(utils.ErrInvalidExportFormat.Code))
+	})
+}
+
+func TestProductImportJob(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		var file strings.Builder
+		file.WriteString("sku,name,price\n")
+		for i := range 600 {
+			fmt.Fprintf(&file, "SKU-%03d,Produk %d,%d\n", i, i, 1000+i)
+		}
+		file.WriteString("SKU-000,Duplikat,1\n")
+
+		r := env.importFile(t, "", token, "text/csv", file.String())
+		expect(t, r, http.StatusAccepted, string(utils.MsgImportStarted))
+		if r.data()["total"] != float64(601) || r.data()["type"] != "product_import" {
+			t.Fatalf("job = %v", r.data())
+		}
+		path := "/api/jobs/" + r.data()["id"].(string)
+
+		// Job hanya terlihat oleh pembuatnya (dan admin)
+		other := env.login(t, "siti@example.com")
+		expect(t, env.do(t, http.MethodGet, path, other, nil), http.StatusNotFound, string(utils.ErrJobNotFound.Code))
+
+		deadline := time.Now().Add(30 * time.Second)
+		for {
+			r = env.do(t, http.MethodGet, path, token, nil)
+			expect(t, r, http.StatusOK, string(utils.MsgJobRetrieved))
+			if r.data()["status"] != string(jobs.StatusRunning) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("job belum selesai: %v", r.data())
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		job := r.data()
+		result := job["result"].(map[string]any)
+		if job["status"] != string(jobs.StatusSucceeded) || job["processed"] != float64(601) || result["created"] != float64(600) || result["failed"] != float64(1) {
+			t.Fatalf("job = %v", job)
+		}
+
+		r = env.do(t, http.MethodGet, "/api/products?page=1&limit=1", token, nil)
+		if r.Body["count"] != float64(600) {
+			t.Fatalf("count = %v", r.Body["count"])
+		}
+	})
+}
+
+func TestProductHistoryAndTrash(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		admin := env.login(t, "budi@example.com")
+		env.promote(t, "budi@example.com")
+		user := env.login(t, "siti@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/categories", admin, fiber.Map{"name": "Elektronik"})
+		expect(t, r, http.StatusOK, string(utils.MsgCategoryCreated))
+		categoryID := r.data()["id"].(string)
+
+		// Produk milik user, diubah oleh admin
+		r = env.do(t, http.MethodPost, "/api/products", user, fiber.Map{"name": "Laptop", "price": 100, "category_ids": []string{categoryID}})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		path := "/api/products/" + r.data()["id"].(string)
+
+		r = env.doMatch(t, http.MethodPatch, path, admin, "*", fiber.Map{"price": 90, "description": "Bekas", "category_ids": []string{}})
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		// Update tanpa perubahan tidak membuat versi baru
+		r = env.doMatch(t, http.MethodPatch, path, admin, "*", fiber.Map{"price": 90})
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+
+		r = env.do(t, http.MethodGet, path+"/history", user, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgHistoryRetrieved))
+		if len(r.list()) != 2 {
+			t.Fatalf("history = %v", r.list())
+		}
+		latest, first := r.list()[0].(map[string]any), r.list()[1].(map[string]any)
+		changes := latest["changes"].(map[string]any)
+		if latest["version"] != float64(2) || latest["action"] != string(models.RevisionUpdated) || len(changes) != 3 {
+			t.Fatalf("versi 2 = %v", latest)
+		}
+		if price := changes["price"].(map[string]any); price["from"] != float64(100) || price["to"] != float64(90) {
+			t.Fatalf("changes = %v", changes)
+		}
+		if first["version"] != float64(1) || first["action"] != string(models.RevisionCreated) || first["user_id"] == latest["user_id"] {
+			t.Fatalf("versi 1 = %v", first)
+		}
+
+		r = env.do(t, http.MethodPost, path+"/revert/1", user, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductReverted))
+		product, revision := r.data()["product"].(map[string]any), r.data()["revision"].(map[string]any)
+		if product["price"] != float64(100) || product["description"] != "" || len(product["categories"].([]any)) != 1 {
+			t.Fatalf("product = %v", product)
+		}
+		if revision["version"] != float64(3) || revision["action"] != string(models.RevisionReverted) || revision["reverted_from"] != float64(1) {
+			t.Fatalf("revision = %v", revision)
+		}
+		for _, version := range []string{"99", "0", "abc"} {
+			expect(t, env.do(t, http.MethodPost, path+"/revert/"+version, user, nil), http.StatusNotFound, string(utils.ErrRevisionNotFound.Code))
+		}
+
+		// Trash: hanya admin
+		expect(t, env.doMatch(t, http.MethodDelete, path, user, "*", nil), http.StatusOK, string(utils.MsgProductDeleted))
+		expect(t, env.do(t, http.MethodGet, path, user, nil), http.StatusNotFound, string(utils.ErrProductNotFound.Code))
+		expect(t, env.do(t, http.MethodGet, "/api/products/trash", user, nil), http.StatusForbidden, string(utils.ErrForbidden.Code))
+		expect(t, env.do(t, http.MethodPost, path+"/restore", user, nil), http.StatusForbidden, string(utils.ErrForbidden.Code))
+
+		r = env.do(t, http.MethodGet, "/api/products/trash", admin, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgTrashRetrieved))
+		expectNames(t, r, "name", "Laptop")
+		r = env.do(t, http.MethodGet, "/api/products", admin, nil)
+		if len(r.list()) != 0 {
+			t.Fatalf("produk di trash ikut di list: %v", r.list())
+		}
+
+		r = env.do(t, http.MethodPost, path+"/restore", admin, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductRestored))
+		expect(t, env.do(t, http.MethodPost, path+"/restore", admin, nil), http.StatusNotFound, string(utils.ErrProductNotFound.Code))
+		expect(t, env.do(t, http.MethodGet, path, user, nil), http.StatusOK, string(utils.MsgProductRetrieved))
+		r = env.do(t, http.MethodGet, "/api/products/trash", admin, nil)
+		if len(r.list()) != 0 {
+			t.Fatalf("trash = %v", r.list())
+		}
+
+		r = env.do(t, http.MethodGet, path+"/history?sort=version", user, nil)
+		var actions []string
+		for _, item := range r.list() {
+			actions = append(actions, item.(map[string]any)["action"].(string))
+		}
+		if !slices.Equal(actions, []string{"created", "updated", "reverted", "deleted", "restored"}) {
+			t.Fatalf("actions = %v", actions)
+		}
+	})
+}
+
+// patch mengirim body PATCH mentah dengan Content-Type tertentu (If-Match: *)
+func (e *testEnv) patch(t *testing.T, path, token, contentType, body string) response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, contentType)
+	req.Header.Set(fiber.HeaderIfMatch, "*")
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	return e.send(t, req)
+}
+
+func TestProductPatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+		env.promote(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/categories", token, fiber.Map{"name": "Minuman"})
+		expect(t, r, http.StatusOK, string(utils.MsgCategoryCreated))
+		category := r.data()["id"].(string)
+
+		r = env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Kopi", "description": "Arabika", "price": 25000, "sku": "kopi-1", "low_stock_threshold": 3})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		path := "/api/products/" + r.data()["id"].(string)
+
+		// Merge patch: null menghapus nilai, harga 0 untuk produk gratis
+		r = env.patch(t, path, token, "application/merge-patch+json", `{"description": null, "price": 0}`)
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		if data := r.data(); data["description"] != "" || data["price"] != float64(0) || data["sku"] != "KOPI-1" || data["low_stock_threshold"] != float64(3) {
+			t.Fatalf("product = %v", data)
+		}
+		r = env.patch(t, path, token, "application/merge-patch+json", `{"sku": null, "low_stock_threshold": null}`)
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		if data := r.data(); data["sku"] != nil || data["low_stock_threshold"] != nil || data["name"] != "Kopi" {
+			t.Fatalf("product = %v", data)
+		}
+
+		r = env.patch(t, path, token, "application/json-patch+json", `[
+			{"op": "test", "path": "/name", "value": "Kopi"},
+			{"op": "replace", "path": "/name", "value": "Kopi Tubruk"},
+			{"op": "add", "path": "/category_ids/-", "value": "`+category+`"}
+		]`)
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		if data := r.data(); data["name"] != "Kopi Tubruk" || len(data["categories"].([]any)) != 1 {
+			t.Fatalf("product = %v", data)
+		}
+
+		r = env.patch(t, path, token, "application/json-patch+json", `[{"op": "replace", "path": "/price", "value": 1}, {"op": "test", "path": "/name", "value": "Kopi"}]`)
+		expect(t, r, http.StatusConflict, string(utils.ErrPatchTestFailed.Code))
+		if data := r.Body["data"].(map[string]any); data["op"] != float64(1) {
+			t.Fatalf("data = %v", data)
+		}
+
+		for body, code := range map[string]*utils.AppError{
+			`[{"op": "replace", "path": "/stock", "value": 5}]`:  utils.ErrInvalidPatch,
+			`[{"op": "remove", "path": "/name"}]`:                utils.ErrProductNameAndPriceRequired,
+			`[{"op": "replace", "path": "/price", "value": -1}]`: utils.ErrInvalidPrice,
+			`{"op": "remove"}`: utils.ErrInvalidPatch,
+		} {
+			expect(t, env.patch(t, path, token, "application/json-patch+json", body), code.Status, string(code.Code))
+		}
+		r = env.patch(t, path, token, "application/merge-patch+json", `{"stock": 5}`)
+		expect(t, r, http.StatusBadRequest, string(utils.ErrInvalidPatch.Code))
+		if reason := r.Body["data"].(map[string]any)["reason"]; reason != `field "stock" cannot be changed` {
+			t.Fatalf("reason = %v", reason)
+		}
+		expect(t, env.patch(t, path, token, "text/plain", `name=Teh`), http.StatusUnsupportedMediaType, string(utils.ErrUnsupportedPatch.Code))
+
+		// PUT mengganti semua field: yang tidak dikirim dikosongkan, yang wajib harus ada
+		expect(t, env.doMatch(t, http.MethodPut, path, token, "*", fiber.Map{"name": "Kopi"}), http.StatusBadRequest, string(utils.ErrProductNameAndPriceRequired.Code))
+		r = env.doMatch(t, http.MethodPut, path, token, "*", fiber.Map{"name": "Teh", "price": 5000})
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		if data := r.data(); data["name"] != "Teh" || len(data["categories"].([]any)) != 0 {
+			t.Fatalf("product = %v", data)
+		}
+	})
+}
+
+func TestBankPatch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		expect(t, env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "BCA"}), http.StatusBadRequest, string(utils.ErrBankFieldsRequired.Code))
+		r := env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "BCA", "account_no": "111", "currency": "USD"})
+		expect(t, r, http.StatusOK, string(utils.MsgBankAdded))
+		path := "/api/bank/" + r.data()["id"].(string)
+
+		// Dulu field yang tidak dikirim di PUT dikosongkan diam-diam
+		expect(t, env.doMatch(t, http.MethodPut, path, token, "*", fiber.Map{"bank_name": "BCA Syariah"}), http.StatusBadRequest, string(utils.ErrBankFieldsRequired.Code))
+
+		r = env.patch(t, path, token, "application/merge-patch+json", `{"bank_name": "BCA Syariah"}`)
+		expect(t, r, http.StatusOK, string(utils.MsgBankUpdated))
+		if data := r.data(); data["bank_name"] != "BCA Syariah" || data["account_no"] != "111" || data["currency"] != "USD" {
+			t.Fatalf("bank = %v", data)
+		}
+
+		r = env.patch(t, path, token, "application/json-patch+json", `[{"op": "replace", "path": "/account_no", "value": "112"}]`)
+		expect(t, r, http.StatusOK, string(utils.MsgBankUpdated))
+		if r.data()["account_no"] != "112" {
+			t.Fatalf("bank = %v", r.data())
+		}
+
+		expect(t, env.patch(t, path, token, "application/merge-patch+json", `{"account_no": null}`), http.StatusBadRequest, string(utils.ErrBankFieldsRequired.Code))
+		expect(t, env.patch(t, path, token, "application/merge-patch+json", `{"currency": "IDR"}`), http.StatusBadRequest, string(utils.ErrInvalidPatch.Code))
+		expect(t, env.patch(t, "/api/bank/"+uuid.NewString(), token, "application/merge-patch+json", `{}`), http.StatusNotFound, string(utils.ErrBankNotFound.Code))
+	})
+}
+
+// getIfNoneMatch mengirim GET dengan header If-None-Match
+func (e *testEnv) getIfNoneMatch(t *testing.T, path, token, ifNoneMatch string) response {
+	t.Helper()
+
+	req := e.request(t, http.MethodGet, path, token, nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, ifNoneMatch)
+	return e.send(t, req)
+}
+
+func expectETag(t *testing.T, r response, want string) {
+	t.Helper()
+	if got := r.Header.Get(fiber.HeaderETag); got != want {
+		t.Fatalf("ETag = %q, want %q", got, want)
+	}
+}
+
+func TestProductETags(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Kaos", "price": 100000, "options": []fiber.Map{{"name": "size", "values": []string{"S", "M"}}}})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		expectETag(t, r, `"1"`)
+		id := r.data()["id"].(string)
+		path := "/api/products/" + id
+
+		r = env.do(t, http.MethodGet, path, token, nil)
+		expectETag(t, r, `"1"`)
+		if r.data()["version"] != float64(1) {
+			t.Fatalf("product = %v", r.data())
+		}
+		for _, header := range []string{`"1"`, `W/"1"`, `"0", "1"`, `*`} {
+			r = env.getIfNoneMatch(t, path, token, header)
+			if r.Status != http.StatusNotModified || r.Body != nil {
+				t.Fatalf("If-None-Match %s: status = %d, body %v", header, r.Status, r.Body)
+			}
+			expectETag(t, r, `"1"`)
+		}
+		expect(t, env.getIfNoneMatch(t, path, token, `"0"`), http.StatusOK, string(utils.MsgProductRetrieved))
+
+		// Tanpa If-Match: 428, ETag lama atau lemah: 412
+		body := fiber.Map{"name": "Kaos Polos", "price": 90000, "options": []fiber.Map{{"name": "size", "values": []string{"S", "M"}}}}
+		expect(t, env.do(t, http.MethodPut, path, token, body), http.StatusPreconditionRequired, string(utils.ErrPreconditionRequired.Code))
+		expect(t, env.do(t, http.MethodDelete, path, token, nil), http.StatusPreconditionRequired, string(utils.ErrPreconditionRequired.Code))
+		r = env.doMatch(t, http.MethodPut, path, token, `"1"`, body)
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		expectETag(t, r, `"2"`)
+
+		expect(t, env.doMatch(t, http.MethodPut, path, token, `"1"`, body), http.StatusPreconditionFailed, string(utils.ErrPreconditionFailed.Code))
+		expect(t, env.doMatch(t, http.MethodPatch, path, token, `W/"2"`, fiber.Map{"price": 1}), http.StatusPreconditionFailed, string(utils.ErrPreconditionFailed.Code))
+		expect(t, env.doMatch(t, http.MethodDelete, path, token, `"1"`, nil), http.StatusPreconditionFailed, string(utils.ErrPreconditionFailed.Code))
+		expect(t, env.getIfNoneMatch(t, path, token, `"1"`), http.StatusOK, string(utils.MsgProductRetrieved))
+
+		// PUT tanpa perubahan tidak menaikkan version
+		expectETag(t, env.doMatch(t, http.MethodPut, path, token, `"1", "2"`, body), `"2"`)
+
+		// Stok, varian dan gambar ikut di response GET, jadi ikut menaikkan version
+		r = env.do(t, http.MethodPost, path+"/movements", token, fiber.Map{"type": "receive", "quantity": 5})
+		expectETag(t, r, `"3"`)
+		// Varian dan gambar wajib If-Match dengan ETag produk
+		variant := fiber.Map{"sku": "kaos-s", "attributes": fiber.Map{"size": "S"}}
+		expect(t, env.do(t, http.MethodPost, path+"/variants", token, variant), http.StatusPreconditionRequired, string(utils.ErrPreconditionRequired.Code))
+		expect(t, env.doMatch(t, http.MethodPost, path+"/variants", token, `"3"`, variant), http.StatusOK, string(utils.MsgVariantCreated))
+		expectETag(t, env.do(t, http.MethodGet, path, token, nil), `"4"`)
+		failed := string(utils.ErrPreconditionFailed.Code)
+		expect(t, env.doMatch(t, http.MethodPost, path+"/variants", token, `"3"`, fiber.Map{"sku": "kaos-m", "attributes": fiber.Map{"size": "M"}}), http.StatusPreconditionFailed, failed)
+		expect(t, env.doMatch(t, http.MethodPut, path+"/images", token, `"3"`, fiber.Map{"image_ids": []string{}}), http.StatusPreconditionFailed, failed)
+		expect(t, env.do(t, http.MethodPut, path+"/images", token, fiber.Map{"image_ids": []string{}}), http.StatusPreconditionRequired, string(utils.ErrPreconditionRequired.Code))
+		expectETag(t, env.do(t, http.MethodGet, path, token, nil), `"4"`)
+
+		// Version produk dicocokkan lagi di transaksi yang sama dengan perubahan varian
+		staleVariant := &models.ProductVariant{ProductID: uuid.MustParse(id), SKU: "KAOS-M", Attributes: map[string]string{"size": "M"}}
+		if err := env.handler.Variants.Create(context.Background(), staleVariant, 3); !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("stale variant create err = %v", err)
+		}
+		if variants, _ := env.handler.Variants.ListByProduct(context.Background(), uuid.MustParse(id)); len(variants) != 1 {
+			t.Fatalf("variants = %v", variants)
+		}
+		expectETag(t, env.do(t, http.MethodGet, path, token, nil), `"4"`)
+
+		// Request lain menyimpan di antara dibaca dan disimpan: repository menolak version lama
+		stale, err := env.handler.Products.FindByID(context.Background(), uuid.MustParse(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expect(t, env.doMatch(t, http.MethodPatch, path, token, `"4"`, fiber.Map{"price": 80000}), http.StatusOK, string(utils.MsgProductUpdated))
+		stale.Price = 1
+		if err := env.handler.Products.Update(context.Background(), stale, &models.ProductRevision{}); !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("stale update err = %v", err)
+		}
+		if err := env.handler.Products.Delete(context.Background(), stale.ID, 4, &models.ProductRevision{}); !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("stale delete err = %v", err)
+		}
+
+		expect(t, env.doMatch(t, http.MethodPost, path+"/revert/1", token, `"4"`, nil), http.StatusPreconditionFailed, string(utils.ErrPreconditionFailed.Code))
+		r = env.do(t, http.MethodPost, path+"/revert/1", token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductReverted))
+		expectETag(t, r, `"6"`)
+
+		expect(t, env.doMatch(t, http.MethodDelete, path, token, `"6"`, nil), http.StatusOK, string(utils.MsgProductDeleted))
+	})
+}
+
+func TestBankETags(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/bank", token, fiber.Map{"bank_name": "BCA", "account_no": "111"})
+		expect(t, r, http.StatusOK, string(utils.MsgBankAdded))
+		expectETag(t, r, `"1"`)
+		id := r.data()["id"].(string)
+		path := "/api/bank/" + id
+
+		r = env.do(t, http.MethodGet, path, token, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgBankRetrieved))
+		expectETag(t, r, `"1"`)
+		if r.data()["account_no"] != "111" || r.data()["version"] != float64(1) {
+			t.Fatalf("bank = %v", r.data())
+		}
+		if r = env.getIfNoneMatch(t, path, token, `"1"`); r.Status != http.StatusNotModified {
+			t.Fatalf("status = %d, want 304", r.Status)
+		}
+		expect(t, env.do(t, http.MethodGet, "/api/bank/"+uuid.NewString(), token, nil), http.StatusNotFound, string(utils.ErrBankNotFound.Code))
+
+		body := fiber.Map{"bank_name": "BCA Syariah", "account_no": "111"}
+		expect(t, env.do(t, http.MethodPut, path, token, body), http.StatusPreconditionRequired, string(utils.ErrPreconditionRequired.Code))
+		r = env.doMatch(t, http.MethodPut, path, token, `"1"`, body)
+		expect(t, r, http.StatusOK, string(utils.MsgBankUpdated))
+		expectETag(t, r, `"2"`)
+		expect(t, env.doMatch(t, http.MethodPatch, path, token, `"1"`, fiber.Map{"bank_name": "BNI"}), http.StatusPreconditionFailed, string(utils.ErrPreconditionFailed.Code))
+
+		stale, err := env.handler.Banks.FindByID(context.Background(), uuid.MustParse(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Setoran paralel tidak boleh saling menimpa; If-Match opsional di add-money
+		var wg sync.WaitGroup
+		statuses := make(chan int, 20)
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				statuses <- env.do(t, http.MethodPut, path+"/add-money", token, fiber.Map{"amount": 1000}).Status
+			}()
+		}
+		wg.Wait()
+		close(statuses)
+		for status := range statuses {
+			if status != http.StatusOK {
+				t.Fatalf("add-money status = %d", status)
+			}
+		}
+		r = env.do(t, http.MethodGet, path, token, nil)
+		if r.data()["nominal"] != float64(20000) {
+			t.Fatalf("bank = %v", r.data())
+		}
+		expectETag(t, r, `"22"`)
+		expect(t, env.doMatch(t, http.MethodPut, path+"/add-money", token, `"2"`, fiber.Map{"amount": 1}), http.StatusPreconditionFailed, string(utils.ErrPreconditionFailed.Code))
+
+		stale.BankName = "BRI"
+		if err := env.handler.Banks.Update(context.Background(), stale); !errors.Is(err, repository.ErrVersionConflict) {
+			t.Fatalf("stale update err = %v", err)
+		}
+
+		expect(t, env.doMatch(t, http.MethodDelete, path, token, `"2"`, nil), http.StatusPreconditionFailed, string(utils.ErrPreconditionFailed.Code))
+		expect(t, env.doMatch(t, http.MethodDelete, path, token, `"22"`, nil), http.StatusOK, string(utils.MsgBankDeleted))
+		expect(t, env.do(t, http.MethodGet, path, token, nil), http.StatusNotFound, string(utils.ErrBankNotFound.Code))
+	})
+}
+
+func TestBankOwnership(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		owner := env.login(t, "budi@example.com")
+		other := env.login(t, "siti@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/bank", owner, fiber.Map{"bank_name": "BCA", "account_no": "111"})
+		expect(t, r, http.StatusOK, string(utils.MsgBankAdded))
+		path := "/api/bank/" + r.data()["id"].(string)
+
+		// Bank user lain tidak terlihat dan tidak bisa diubah, juga dengan If-Match: *
+		notFound := string(utils.ErrBankNotFound.Code)
+		expect(t, env.do(t, http.MethodGet, path, other, nil), http.StatusNotFound, notFound)
+		expect(t, env.do(t, http.MethodPut, path+"/add-money", other, fiber.Map{"amount": 1000}), http.StatusNotFound, notFound)
+		expect(t, env.doMatch(t, http.MethodPut, path, other, "*", fiber.Map{"bank_name": "BNI", "account_no": "999"}), http.StatusNotFound, notFound)
+		expect(t, env.doMatch(t, http.MethodPatch, path, other, "*", fiber.Map{"bank_name": "BNI"}), http.StatusNotFound, notFound)
+		expect(t, env.doMatch(t, http.MethodDelete, path, other, "*", nil), http.StatusNotFound, notFound)
+
+		r = env.do(t, http.MethodGet, path, owner, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgBankRetrieved))
+		if r.data()["bank_name"] != "BCA" || r.data()["nominal"] != float64(0) || r.data()["version"] != float64(1) {
+			t.Fatalf("bank = %v", r.data())
+		}
+	})
+}
+
+func TestSellers(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		budi := env.login(t, "budi@example.com")
+		siti := env.login(t, "siti@example.com")
+		admin := env.login(t, "admin@example.com")
+		env.promote(t, "admin@example.com")
+
+		// Profil toko: dibuat lalu diganti lewat PUT yang sama
+		expect(t, env.do(t, http.MethodGet, "/api/me/seller", budi, nil), http.StatusNotFound, string(utils.ErrSellerNotFound.Code))
+		expect(t, env.do(t, http.MethodPut, "/api/me/seller", budi, fiber.Map{"store_name": "  "}), http.StatusBadRequest, string(utils.ErrStoreNameRequired.Code))
+		r := env.do(t, http.MethodPut, "/api/me/seller", budi, fiber.Map{"store_name": "Toko Budi"})
+		expect(t, r, http.StatusOK, string(utils.MsgSellerSaved))
+		if r.data()["slug"] != "toko-budi" {
+			t.Fatalf("seller = %v", r.data())
+		}
+		r = env.do(t, http.MethodPut, "/api/me/seller", budi, fiber.Map{"store_name": "Toko Budi Jaya", "slug": "budi-jaya"})
+		expect(t, r, http.StatusOK, string(utils.MsgSellerSaved))
+		r = env.do(t, http.MethodGet, "/api/me/seller", budi, nil)
+		expect(t, r, http.StatusOK, string(utils.MsgSellerRetrieved))
+		if r.data()["store_name"] != "Toko Budi Jaya" || r.data()["slug"] != "budi-jaya" {
+			t.Fatalf("seller = %v", r.data())
+		}
+		expect(t, env.do(t, http.MethodPut, "/api/me/seller", siti, fiber.Map{"store_name": "Siti", "slug": "budi-jaya"}), http.StatusConflict, string(utils.ErrSlugInUse.Code))
+
+		r = env.do(t, http.MethodPost, "/api/products", budi, fiber.Map{"name": "Kopi", "price": 25000, "sku": "KOPI-1", "status": "published"})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		if r.data()["seller_id"] == nil {
+			t.Fatalf("product = %v", r.data())
+		}
+		path := "/api/products/" + r.data()["id"].(string)
+		r = env.do(t, http.MethodPost, "/api/products", siti, fiber.Map{"name": "Teh", "price": 10000})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+
+		// Seller lain hanya bisa membaca
+		forbidden := string(utils.ErrNotProductOwner.Code)
+		expect(t, env.do(t, http.MethodGet, path, siti, nil), http.StatusOK, string(utils.MsgProductRetrieved))
+		expect(t, env.doMatch(t, http.MethodPut, path, siti, "*", fiber.Map{"name": "Kopi Siti", "price": 1}), http.StatusForbidden, forbidden)
+		expect(t, env.doMatch(t, http.MethodPatch, path, siti, "*", fiber.Map{"price": 1}), http.StatusForbidden, forbidden)
+		expect(t, env.doMatch(t, http.MethodDelete, path, siti, "*", nil), http.StatusForbidden, forbidden)
+		expect(t, env.do(t, http.MethodPost, path+"/movements", siti, fiber.Map{"type": "receive", "quantity": 5}), http.StatusForbidden, forbidden)
+		expect(t, env.do(t, http.MethodPost, path+"/variants", siti, fiber.Map{"sku": "KOPI-S"}), http.StatusForbidden, forbidden)
+		expect(t, env.do(t, http.MethodPost, path+"/revert/1", siti, nil), http.StatusForbidden, forbidden)
+
+		// Import SKU milik seller lain gagal per baris
+		r = env.importFile(t, "", siti, "text/csv", "sku,name,price\nKOPI-1,Kopi Siti,1\nTEH-2,Teh Hijau,12000\n")
+		expect(t, r, http.StatusOK, string(utils.MsgImportCompleted))
+		if r.data()["created"] != float64(1) || r.data()["failed"] != float64(1) {
+			t.Fatalf("report = %v", r.data())
+		}
+		if rowErr := r.data()["errors"].([]any)[0].(map[string]any); rowErr["code"] != forbidden || rowErr["row"] != float64(2) {
+			t.Fatalf("errors = %v", r.data()["errors"])
+		}
+
+		// Pemilik dan admin boleh mengubah
+		expect(t, env.doMatch(t, http.MethodPatch, path, budi, "*", fiber.Map{"price": 26000}), http.StatusOK, string(utils.MsgProductUpdated))
+		r = env.do(t, http.MethodPost, "/api/products", budi, fiber.Map{"name": "Kopi Draft", "price": 1})
+		expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+		expect(t, env.doMatch(t, http.MethodPatch, path, admin, "*", fiber.Map{"price": 27000}), http.StatusOK, string(utils.MsgProductUpdated))
+
+		expectNames(t, env.do(t, http.MethodGet, "/api/me/products?sort=name", budi, nil), "name", "Kopi", "Kopi Draft")
+		expectNames(t, env.do(t, http.MethodGet, "/api/me/products?sort=name", siti, nil), "name", "Teh", "Teh Hijau")
+		expectNames(t, env.do(t, http.MethodGet, "/api/me/products", admin, nil), "name")
+
+		// Halaman publik seller tanpa login, berdasarkan slug atau ID; hanya produk published
+		r = env.do(t, http.MethodGet, "/sellers/budi-jaya", "", nil)
+		expect(t, r, http.StatusOK, string(utils.MsgSellerRetrieved))
+		expectNames(t, env.do(t, http.MethodGet, "/sellers/budi-jaya/products", "", nil), "name", "Kopi")
+		expectNames(t, env.do(t, http.MethodGet, "/sellers/"+r.data()["id"].(string)+"/products", "", nil), "name", "Kopi")
+		// Siti belum punya profil toko
+		expect(t, env.do(t, http.MethodGet, "/sellers/siti/products", "", nil), http.StatusNotFound, string(utils.ErrSellerNotFound.Code))
+	})
+}
+
+func TestCatalog(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, env *testEnv) {
+		token := env.login(t, "budi@example.com")
+		env.promote(t, "budi@example.com")
+
+		r := env.do(t, http.MethodPost, "/api/categories", token, fiber.Map{"name": "Minuman"})
+		expect(t, r, http.StatusOK, string(utils.MsgCategoryCreated))
+		category := r.data()["id"].(string)
+
+		create := func(input fiber.Map) string {
+			t.Helper()
+			input["price"] = 10000
+			r := env.do(t, http.MethodPost, "/api/products", token, input)
+			expect(t, r, http.StatusOK, string(utils.MsgProductCreated))
+			return "/products/" + r.data()["id"].(string)
+		}
+		kopi := create(fiber.Map{"name": "Kopi", "status": "published", "sku": "KOPI-1", "category_ids": []string{category}, "options": []fiber.Map{{"name": "size", "values": []string{"S"}}}})
+		teh := create(fiber.Map{"name": "Teh"})
+		create(fiber.Map{"name": "Susu", "status": "archived"})
+		jus := create(fiber.Map{"name": "Jus", "status": "published", "publish_at": time.Now().Add(time.Hour)})
+		air := create(fiber.Map{"name": "Air", "status": "published", "publish_at": time.Now().Add(-time.Hour)})
+		expect(t, env.do(t, http.MethodPost, "/api/products", token, fiber.Map{"name": "Soda", "price": 1, "status": "hidden"}), http.StatusBadRequest, string(utils.ErrInvalidProductStatus.Code))
+
+		expect(t, env.do(t, http.MethodPost, "/api"+kopi+"/movements", token, fiber.Map{"type": "receive", "quantity": 5}), http.StatusOK, "")
+		expect(t, env.doMatch(t, http.MethodPost, "/api"+kopi+"/variants", token, "*", fiber.Map{"sku": "KOPI-S", "attributes": fiber.Map{"size": "S"}}), http.StatusOK, string(utils.MsgVariantCreated))
+
+		// Produk baru default draft; API internal tetap melihat semua status
+		r = env.do(t, http.MethodGet, "/api"+teh, token, nil)
+		if r.data()["status"] != "draft" || r.data()["publish_at"] != nil {
+			t.Fatalf("product = %v", r.data())
+		}
+		expectNames(t, env.do(t, http.MethodGet, "/api/products?status=draft", token, nil), "name", "Teh")
+		expect(t, env.do(t, http.MethodGet, "/api/products?status=hidden", token, nil), http.StatusBadRequest, string(utils.ErrInvalidFilter.Code))
+
+		// Katalog publik tanpa login: hanya published yang sudah tayang, tanpa field internal
+		r = env.do(t, http.MethodGet, "/catalog/products?sort=name", "", nil)
+		expectNames(t, r, "name", "Air", "Kopi")
+		if cache := r.Header.Get(fiber.HeaderCacheControl); !strings.Contains(cache, "public") || !strings.Contains(cache, "s-maxage=") {
+			t.Fatalf("Cache-Control = %q", cache)
+		}
+		item := r.list()[1].(map[string]any)
+		for _, field := range []string{"stock", "reserved", "low_stock_threshold", "status", "publish_at", "version", "created_at"} {
+			if _, ok := item[field]; ok {
+				t.Fatalf("field %s ikut di katalog: %v", field, item)
+			}
+		}
+		if item["in_stock"] != true || item["sku"] != "KOPI-1" {
+			t.Fatalf("item = %v", item)
+		}
+
+		// ETag lemah dari isi response untuk revalidasi CDN
+		tag := r.Header.Get(fiber.HeaderETag)
+		if !strings.HasPrefix(tag, `W/"`) {
+			t.Fatalf("ETag = %q", tag)
+		}
+		expect(t, env.getIfNoneMatch(t, "/catalog/products?sort=name", "", tag), http.StatusNotModified, "")
+
+		expectNames(t, env.do(t, http.MethodGet, "/catalog/products?category=minuman", "", nil), "name", "Kopi")
+		expectNames(t, env.do(t, http.MethodGet, "/catalog/products?q=kopi", "", nil), "name", "Kopi")
+
+		r = env.do(t, http.MethodGet, "/catalog"+kopi, "", nil)
+		expect(t, r, http.StatusOK, string(utils.MsgProductRetrieved))
+		variant := r.data()["variants"].([]any)[0].(map[string]any)
+		if _, ok := variant["stock"]; ok || variant["in_stock"] != false || variant["price"] != float64(10000) {
+			t.Fatalf("variant = %v", variant)
+		}
+		if _, ok := r.data()["stock"]; ok || r.data()["in_stock"] != true {
+			t.Fatalf("product = %v", r.data())
+		}
+		if !strings.HasPrefix(r.Header.Get(fiber.HeaderCacheControl), "public") || r.Header.Get(fiber.HeaderETag) == "" {
+			t.Fatalf("header = %v", r.Header)
+		}
+		expect(t, env.do(t, http.MethodGet, "/catalog"+teh, "", nil), http.StatusNotFound, string(utils.ErrProductNotFound.Code))
+		expect(t, env.do(t, http.MethodGet, "/catalog"+jus, "", nil), http.StatusNotFound, string(utils.ErrProductNotFound.Code))
+
+		// PUT tanpa status mempertahankan status tersimpan
+		r = env.doMatch(t, http.MethodPut, "/api"+air, token, "*", fiber.Map{"name": "Air Mineral", "price": 5000})
+		expect(t, r, http.StatusOK, string(utils.MsgProductUpdated))
+		if r.data()["status"] != "published" {
+			t.Fatalf("product = %v", r.data())
+		}
+		expect(t, env.do(t, http.MethodGet, "/catalog"+air, "", nil), http.StatusOK, string(utils.MsgProductRetrieved))
+
+		// Archive menarik produk dari katalog dan tercatat di riwayat
+		expect(t, env.doMatch(t, http.MethodPatch, "/api"+kopi, token, "*", fiber.Map{"status": "archived"}), http.StatusOK, string(utils.MsgProductUpdated))
+		expect(t, env.do(t, http.MethodGet, "/catalog"+kopi, "", nil), http.StatusNotFound, string(utils.ErrProductNotFound.Code))
+		r = env.do(t, http.MethodGet, "/api"+kopi+"/history", token, nil)
+		changes := r.list()[0].(map[string]any)["changes"].(map[string]any)
+		if len(changes) != 1 || changes["status"].(map[string]any)["to"] != "archived" {
+			t.Fatalf("changes = %v", changes)
+		}
+	})
+}
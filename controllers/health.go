@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"learn_project/buildinfo"
+	"learn_project/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batas waktu setiap pengecekan dependency di readiness probe
+const readinessTimeout = 2 * time.Second
+
+// Healthz hanya menandakan proses masih hidup (liveness probe)
+func Healthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Readyz mengecek apakah instance siap menerima traffic dengan menjalankan
+// semua ReadinessChecks (mis. ping database dan status migrasi).
+// Selama graceful shutdown readiness selalu gagal.
+func (h *Handler) Readyz(c *fiber.Ctx) error {
+	ready := true
+	checks := fiber.Map{}
+
+	if health.ShuttingDown() {
+		ready = false
+		checks["shutdown"] = fiber.Map{"status": "fail", "error": "server is shutting down"}
+	}
+
+	for _, check := range h.ReadinessChecks {
+		ctx, cancel := context.WithTimeout(c.UserContext(), readinessTimeout)
+		start := time.Now()
+		err := check.Run(ctx)
+		cancel()
+
+		result := fiber.Map{"status": "ok", "latency_ms": time.Since(start).Milliseconds()}
+		if err != nil {
+			ready = false
+			result["status"] = "fail"
+			result["error"] = err.Error()
+		}
+		checks[check.Name] = result
+	}
+
+	status, state := fiber.StatusOK, "ok"
+	if !ready {
+		status, state = fiber.StatusServiceUnavailable, "fail"
+	}
+	return c.Status(status).JSON(fiber.Map{"status": state, "checks": checks})
+}
+
+// Version mengembalikan informasi build yang diisi lewat -ldflags
+func Version(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"version":    buildinfo.Version,
+		"commit":     buildinfo.Commit,
+		"build_time": buildinfo.BuildTime,
+		"start_time": buildinfo.StartTime.UTC().Format(time.RFC3339),
+		"uptime":     time.Since(buildinfo.StartTime).Round(time.Second).String(),
+		"go_version": runtime.Version(),
+	})
+}
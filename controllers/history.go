@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetProductHistory mengembalikan versi-versi produk (siapa mengubah apa dan kapan),
+// terbaru lebih dulu
+func (h *Handler) GetProductHistory(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+
+	q, err := h.listQuery(c, repository.RevisionListSpec)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.Products.Revisions(c.UserContext(), product.ID, q)
+	if err != nil {
+		return utils.ErrHistoryList.Wrap(err)
+	}
+
+	return utils.ResponseSuccessManyData(c, utils.MsgHistoryRetrieved, page.Items, q.Page, q.Limit, int(page.Count), page.NextCursor)
+}
+
+// RevertProduct mengembalikan isi produk ke snapshot versi :version dan mencatatnya
+// sebagai versi baru. Stok dan status publikasi tidak ikut dikembalikan; kategori yang
+// sudah dihapus dilewati.
+func (h *Handler) RevertProduct(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	user, err := h.authorizeProduct(c, product)
+	if err != nil {
+		return err
+	}
+	// POST yang menyebut versi tujuan secara eksplisit, jadi If-Match opsional
+	expected, err := checkIfMatch(c, product.Version, false)
+	if err != nil {
+		return err
+	}
+
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil || version < 1 {
+		return utils.ErrRevisionNotFound
+	}
+	revision, err := h.Products.FindRevision(c.UserContext(), product.ID, version)
+	if errors.Is(err, repository.ErrNotFound) {
+		return utils.ErrRevisionNotFound
+	}
+	if err != nil {
+		return utils.ErrProductRevert.Wrap(err)
+	}
+
+	snapshot := revision.Snapshot
+	categories, err := h.Categories.FindByIDs(c.UserContext(), snapshot.CategoryIDs)
+	if err != nil {
+		return utils.ErrCategoryList.Wrap(err)
+	}
+	// Varian yang dibuat setelah versi itu harus tetap cocok dengan option lamanya
+	if err := h.checkVariantOptions(c.UserContext(), product.ID, snapshot.Options); err != nil {
+		return err
+	}
+
+	before := *product
+	product.Name = snapshot.Name
+	product.Description = snapshot.Description
+	product.Price = snapshot.Price
+	product.SKU = snapshot.SKU
+	product.Categories = categories
+	product.Options = snapshot.Options
+	product.LowStockThreshold = snapshot.LowStockThreshold
+	product.Version = expected
+
+	change := models.ProductRevision{UserID: &user.ID, Action: models.RevisionReverted, RevertedFrom: &version}
+	err = h.Products.Update(c.UserContext(), product, &change)
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return utils.ErrSKUInUse
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case err != nil:
+		return utils.ErrProductRevert.Wrap(err)
+	}
+	h.publishStockEvents(c.UserContext(), &before, product)
+
+	setETag(c, product.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgProductReverted, fiber.Map{
+		"product":  productData(product),
+		"revision": change,
+	})
+}
+
+// GetTrash mengembalikan produk yang sudah dihapus (admin), terakhir dihapus lebih dulu
+func (h *Handler) GetTrash(c *fiber.Ctx) error {
+	q, err := h.listQuery(c, repository.TrashListSpec)
+	if err != nil {
+		return err
+	}
+	q.IncludeDeleted = true
+
+	page, err := h.Products.List(c.UserContext(), q, repository.ProductFilter{Trash: true})
+	if err != nil {
+		return utils.ErrProductList.Wrap(err)
+	}
+
+	return utils.ResponseSuccessManyData(c, utils.MsgTrashRetrieved, page.Items, q.Page, q.Limit, int(page.Count), page.NextCursor)
+}
+
+// RestoreProduct mengeluarkan produk dari trash (admin)
+func (h *Handler) RestoreProduct(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrProductNotFound
+	}
+
+	product, err := h.Products.Restore(c.UserContext(), id, &models.ProductRevision{UserID: &user.ID})
+	if errors.Is(err, repository.ErrNotFound) {
+		return utils.ErrProductNotFound
+	}
+	if err != nil {
+		return utils.ErrProductRestore.Wrap(err)
+	}
+
+	setETag(c, product.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgProductRestored, productData(product))
+}
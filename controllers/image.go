@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"slices"
+	"time"
+
+	"learn_project/imaging"
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/storage"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// imageCacheControl: file gambar tidak pernah berubah untuk ID yang sama
+// (upload baru selalu ID baru), jadi boleh di-cache selamanya oleh browser dan CDN
+const imageCacheControl = "public, max-age=31536000, immutable"
+
+// privateImageCacheControl: gambar produk yang belum tayang hanya boleh di-cache
+// browser pemiliknya dan selalu direvalidasi, supaya tidak tersimpan di CDN
+const privateImageCacheControl = "private, no-cache"
+
+// Struct untuk request body ReorderImages
+type ReorderImagesInput struct {
+	ImageIDs []uuid.UUID `json:"image_ids"`
+}
+
+// UploadImage menerima multipart form dengan field "image" (JPEG atau PNG)
+func (h *Handler) UploadImage(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.authorizeProduct(c, product); err != nil {
+		return err
+	}
+	version, err := checkProductMatch(c, product)
+	if err != nil {
+		return err
+	}
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		return utils.ErrImageRequired
+	}
+	if h.MaxImageSize > 0 && header.Size > h.MaxImageSize {
+		return utils.ErrImageTooLarge.WithData(fiber.Map{"max_size": h.MaxImageSize})
+	}
+	data, err := readFormFile(header)
+	if err != nil {
+		return utils.ErrImageRequired
+	}
+
+	// Format ditentukan dari isi file, Content-Type dari client diabaikan
+	processed, err := imaging.Process(data)
+	switch {
+	case errors.Is(err, imaging.ErrUnsupported):
+		return utils.ErrUnsupportedImage
+	case errors.Is(err, imaging.ErrTooManyPixels):
+		return utils.ErrImageTooLarge.WithData(fiber.Map{"max_pixels": imaging.MaxPixels})
+	case errors.Is(err, imaging.ErrInvalid):
+		return utils.ErrInvalidImage
+	case err != nil:
+		return utils.ErrImageUpload.Wrap(err)
+	}
+
+	image := models.ProductImage{
+		ID:          uuid.New(),
+		ProductID:   product.ID,
+		ContentType: processed.ContentType,
+		Width:       processed.Width,
+		Height:      processed.Height,
+		Size:        int64(len(processed.Files[imaging.Original])),
+	}
+
+	// File ditulis dulu baru metadata, supaya gambar yang tercatat selalu punya file
+	for size, file := range processed.Files {
+		if err := h.Blobs.Put(c.UserContext(), image.Key(size), file, image.ContentType); err != nil {
+			h.deleteImageFiles(c.UserContext(), &image)
+			return utils.ErrImageUpload.Wrap(err)
+		}
+	}
+	if err := h.Images.Create(c.UserContext(), &image, version); err != nil {
+		h.deleteImageFiles(c.UserContext(), &image)
+		switch {
+		case errors.Is(err, repository.ErrVersionConflict):
+			return utils.ErrPreconditionFailed
+		case errors.Is(err, repository.ErrNotFound):
+			return utils.ErrProductNotFound
+		}
+		return utils.ErrImageUpload.Wrap(err)
+	}
+
+	setImageURLs(&image)
+	return utils.ResponseSuccessOneData(c, utils.MsgImageUploaded, image)
+}
+
+// GetImages mengembalikan gambar produk sesuai urutan
+func (h *Handler) GetImages(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+
+	images, err := h.productImages(c.UserContext(), product.ID)
+	if err != nil {
+		return err
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgImagesRetrieved, images)
+}
+
+// ReorderImages mengubah urutan gambar; image_ids harus berisi semua gambar produk
+func (h *Handler) ReorderImages(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.authorizeProduct(c, product); err != nil {
+		return err
+	}
+	version, err := checkProductMatch(c, product)
+	if err != nil {
+		return err
+	}
+
+	var input ReorderImagesInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+
+	images, err := h.productImages(c.UserContext(), product.ID)
+	if err != nil {
+		return err
+	}
+	if len(input.ImageIDs) != len(images) {
+		return utils.ErrInvalidImageOrder
+	}
+	for _, image := range images {
+		if !slices.Contains(input.ImageIDs, image.ID) {
+			return utils.ErrInvalidImageOrder
+		}
+	}
+
+	err = h.Images.Reorder(c.UserContext(), product.ID, input.ImageIDs, version)
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.ErrProductNotFound
+	case err != nil:
+		return utils.ErrImageReorder.Wrap(err)
+	}
+
+	images, err = h.productImages(c.UserContext(), product.ID)
+	if err != nil {
+		return err
+	}
+	return utils.ResponseSuccessOneData(c, utils.MsgImagesReordered, images)
+}
+
+// DeleteImage menghapus gambar beserta semua file ukurannya
+func (h *Handler) DeleteImage(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.authorizeProduct(c, product); err != nil {
+		return err
+	}
+	version, err := checkProductMatch(c, product)
+	if err != nil {
+		return err
+	}
+
+	image, err := h.findImage(c, "imageID")
+	if err != nil {
+		return err
+	}
+	if image.ProductID != product.ID {
+		return utils.ErrImageNotFound
+	}
+
+	err = h.Images.Delete(c.UserContext(), product.ID, image.ID, version)
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.ErrImageNotFound
+	case err != nil:
+		return utils.ErrImageDelete.Wrap(err)
+	}
+	h.deleteImageFiles(c.UserContext(), image)
+
+	return utils.ResponseSuccessOneData(c, utils.MsgImageDeleted, nil)
+}
+
+// ServeImage mengirim file gambar (public, tanpa login) dengan header cache. Seperti
+// katalog, gambar produk yang belum tayang, draft atau archived dianggap tidak ada.
+func (h *Handler) ServeImage(c *fiber.Ctx) error {
+	image, product, err := h.findImageFile(c)
+	if err != nil {
+		return err
+	}
+	if !product.IsPublic(time.Now()) {
+		return utils.ErrImageNotFound
+	}
+	return h.sendImage(c, image, imageCacheControl)
+}
+
+// ServeProductImage mengirim file gambar untuk pemilik produk atau admin, termasuk
+// gambar produk yang belum tampil di katalog
+func (h *Handler) ServeProductImage(c *fiber.Ctx) error {
+	image, product, err := h.findImageFile(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.authorizeProduct(c, product); err != nil {
+		return err
+	}
+	return h.sendImage(c, image, privateImageCacheControl)
+}
+
+// findImageFile mengambil gambar dan produknya dari path parameter id dan size
+func (h *Handler) findImageFile(c *fiber.Ctx) (*models.ProductImage, *models.Product, error) {
+	image, err := h.findImage(c, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(imageSizes(), c.Params("size")) {
+		return nil, nil, utils.ErrImageNotFound
+	}
+	// Gambar produk yang sudah dihapus tidak ditampilkan lagi
+	product, err := h.Products.FindByID(c.UserContext(), image.ProductID)
+	if err != nil {
+		return nil, nil, utils.ErrImageNotFound
+	}
+	return image, product, nil
+}
+
+// sendImage mengirim file gambar ukuran c.Params("size") dengan ETag dan Cache-Control
+func (h *Handler) sendImage(c *fiber.Ctx, image *models.ProductImage, cacheControl string) error {
+	size := c.Params("size")
+	etag := `"` + image.ID.String() + "-" + size + `"`
+	if c.Get(fiber.HeaderIfNoneMatch) == etag {
+		c.Set(fiber.HeaderCacheControl, cacheControl)
+		c.Set(fiber.HeaderETag, etag)
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	object, err := h.Blobs.Get(c.UserContext(), image.Key(size))
+	if errors.Is(err, storage.ErrNotFound) {
+		return utils.ErrImageNotFound
+	}
+	if err != nil {
+		return utils.ErrImageList.Wrap(err)
+	}
+
+	c.Set(fiber.HeaderContentType, image.ContentType)
+	c.Set(fiber.HeaderCacheControl, cacheControl)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return c.SendStream(object.Body, int(object.Size))
+}
+
+// findImage mengambil gambar dari path parameter param
+func (h *Handler) findImage(c *fiber.Ctx, param string) (*models.ProductImage, error) {
+	id, ok := paramID(c, param)
+	if !ok {
+		return nil, utils.ErrImageNotFound
+	}
+
+	image, err := h.Images.FindByID(c.UserContext(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, utils.ErrImageNotFound
+	}
+	if err != nil {
+		return nil, utils.ErrImageList.Wrap(err)
+	}
+	return image, nil
+}
+
+// productImages mengambil gambar produk lengkap dengan URLs
+func (h *Handler) productImages(ctx context.Context, productID uuid.UUID) ([]models.ProductImage, error) {
+	images, err := h.Images.ListByProduct(ctx, productID)
+	if err != nil {
+		return nil, utils.ErrImageList.Wrap(err)
+	}
+	for i := range images {
+		setImageURLs(&images[i])
+	}
+	return images, nil
+}
+
+// deleteImageFiles menghapus semua ukuran gambar dari BlobStore. Gagal hapus hanya
+// dicatat: file yatim tidak terlihat oleh client dan bisa dibersihkan belakangan.
+func (h *Handler) deleteImageFiles(ctx context.Context, image *models.ProductImage) {
+	for _, size := range imageSizes() {
+		if err := h.Blobs.Delete(ctx, image.Key(size)); err != nil {
+			slog.WarnContext(ctx, "gagal menghapus file gambar", "key", image.Key(size), "error", err)
+		}
+	}
+}
+
+func setImageURLs(image *models.ProductImage) {
+	image.URLs = map[string]string{}
+	for _, size := range imageSizes() {
+		image.URLs[size] = "/images/" + image.ID.String() + "/" + size
+	}
+}
+
+// imageSizes adalah semua ukuran yang disimpan untuk setiap gambar
+func imageSizes() []string {
+	sizes := []string{imaging.Original}
+	for _, size := range imaging.Thumbnails {
+		sizes = append(sizes, size.Name)
+	}
+	return sizes
+}
+
+func readFormFile(header *multipart.FileHeader) ([]byte, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"learn_project/events"
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Struct untuk request body CreateMovement
+type MovementInput struct {
+	Type     models.MovementType `json:"type"`
+	Quantity int64               `json:"quantity"` // untuk adjust boleh negatif
+	Reason   string              `json:"reason"`
+}
+
+// CreateMovement mengubah stok produk: receive, adjust, reserve, release atau sell
+func (h *Handler) CreateMovement(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	user, err := h.authorizeProduct(c, product)
+	if err != nil {
+		return err
+	}
+
+	var input MovementInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+
+	movement := models.InventoryMovement{
+		ProductID: product.ID,
+		UserID:    &user.ID,
+		Type:      input.Type,
+		Quantity:  input.Quantity,
+		Reason:    strings.TrimSpace(input.Reason),
+	}
+	if err := validateMovement(movement); err != nil {
+		return err
+	}
+
+	updated, err := h.Products.ApplyMovement(c.UserContext(), &movement)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.ErrProductNotFound
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return h.insufficientStock(c, product)
+	case err != nil:
+		return utils.ErrStockUpdate.Wrap(err)
+	}
+
+	// Bandingkan dengan stok tepat sebelum movement ini, bukan product yang dibaca
+	// di awal request (bisa sudah berubah oleh request lain)
+	stock, reserved := movement.Type.Deltas(movement.Quantity)
+	before := *updated
+	before.Stock -= stock
+	before.Reserved -= reserved
+	h.publishStockEvents(c.UserContext(), &before, updated)
+
+	setETag(c, updated.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgStockUpdated, fiber.Map{
+		"product":  productData(updated),
+		"movement": movement,
+	})
+}
+
+// GetMovements mengembalikan riwayat stok produk, terbaru lebih dulu
+func (h *Handler) GetMovements(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+
+	q, err := h.listQuery(c, repository.MovementListSpec)
+	if err != nil {
+		return err
+	}
+
+	page, err := h.Products.Movements(c.UserContext(), product.ID, q)
+	if err != nil {
+		return utils.ErrMovementList.Wrap(err)
+	}
+
+	return utils.ResponseSuccessManyData(c, utils.MsgMovementsRetrieved, page.Items, q.Page, q.Limit, int(page.Count), page.NextCursor)
+}
+
+func validateMovement(movement models.InventoryMovement) error {
+	if !slices.Contains(models.MovementTypes, movement.Type) {
+		return utils.ErrInvalidMovementType
+	}
+	if movement.Quantity == 0 || (movement.Quantity < 0 && movement.Type != models.MovementAdjust) {
+		return utils.ErrInvalidQuantity
+	}
+	if movement.Type == models.MovementAdjust && movement.Reason == "" {
+		return utils.ErrMovementReasonRequired
+	}
+	return nil
+}
+
+// insufficientStock menyertakan stok terbaru supaya client tahu berapa yang masih tersedia
+func (h *Handler) insufficientStock(c *fiber.Ctx, product *models.Product) error {
+	if latest, err := h.Products.FindByID(c.UserContext(), product.ID); err == nil {
+		product = latest
+	}
+	return utils.ErrInsufficientStock.WithData(fiber.Map{
+		"stock":     product.Stock,
+		"reserved":  product.Reserved,
+		"available": product.Available(),
+	})
+}
+
+// publishStockEvents mengirim event kalau perubahan stok atau threshold melewati
+// low_stock_threshold, ke bawah (stock_low) maupun kembali ke atas (stock_restored)
+func (h *Handler) publishStockEvents(ctx context.Context, before, after *models.Product) {
+	if h.Events == nil || before.IsLowStock() == after.IsLowStock() {
+		return
+	}
+
+	eventType := events.ProductStockRestored
+	if after.IsLowStock() {
+		eventType = events.ProductStockLow
+	}
+	h.Events.Publish(ctx, events.New(eventType, fiber.Map{
+		"product_id":          after.ID,
+		"name":                after.Name,
+		"stock":               after.Stock,
+		"reserved":            after.Reserved,
+		"available":           after.Available(),
+		"low_stock_threshold": after.LowStockThreshold,
+	}))
+}
@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"learn_project/models"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJob mengembalikan status job background (mis. import produk). Hanya pembuat
+// job dan admin yang bisa melihatnya.
+func (h *Handler) GetJob(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	id, ok := paramID(c, "id")
+	if !ok {
+		return utils.ErrJobNotFound
+	}
+	job, ok := h.Jobs.Get(id)
+	if !ok || (job.OwnerID != user.ID && user.Role != models.RoleAdmin) {
+		return utils.ErrJobNotFound
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgJobRetrieved, job)
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"errors"
+
+	"learn_project/listing"
+	"learn_project/models"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// listErrors memetakan error listing ke error API dengan kode yang sama di semua list endpoint
+var listErrors = map[error]*utils.AppError{
+	listing.ErrInvalidPagination: utils.ErrInvalidPagination,
+	listing.ErrInvalidCursor:     utils.ErrInvalidCursor,
+	listing.ErrInvalidSort:       utils.ErrInvalidSort,
+	listing.ErrInvalidFilter:     utils.ErrInvalidFilter,
+}
+
+// listQuery mem-parse sort, filter dan pagination dari query string sesuai spec.
+// include_deleted hanya boleh dipakai admin.
+func (h *Handler) listQuery(c *fiber.Ctx, spec listing.Spec) (listing.Query, error) {
+	q, err := listing.Parse(spec, c.Queries())
+
+	var listErr *listing.Error
+	if errors.As(err, &listErr) {
+		return q, listErrors[listErr.Err].WithData(fiber.Map{"param": listErr.Param, "reason": listErr.Reason})
+	}
+	if err != nil {
+		return q, utils.ErrInvalidInput
+	}
+
+	if q.IncludeDeleted {
+		user, err := h.currentUser(c)
+		if err != nil {
+			return q, err
+		}
+		if user.Role != models.RoleAdmin {
+			return q, utils.ErrForbidden
+		}
+	}
+	return q, nil
+}
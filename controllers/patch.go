@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"learn_project/patch"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// applyPatch menerapkan body PATCH ke doc (field resource yang bisa ditulis) lalu
+// membaca hasilnya ke out. Field di luar doc ditolak supaya patch ke field read-only
+// (mis. stock) tidak diam-diam diabaikan.
+func applyPatch(c *fiber.Ctx, doc, out any) error {
+	current, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	patched, err := patch.Apply(c.Get(fiber.HeaderContentType), current, c.Body())
+	var patchErr *patch.Error
+	switch {
+	case errors.Is(err, patch.ErrUnsupportedType):
+		return utils.ErrUnsupportedPatch
+	case errors.As(err, &patchErr):
+		appErr := utils.ErrInvalidPatch
+		if errors.Is(err, patch.ErrTestFailed) {
+			appErr = utils.ErrPatchTestFailed
+		}
+		data := fiber.Map{"reason": patchErr.Reason}
+		if patchErr.Op >= 0 {
+			data["op"] = patchErr.Op
+		}
+		return appErr.WithData(data)
+	case err != nil:
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return utils.ErrInvalidPatch.WithData(fiber.Map{"reason": patchedReason(err)})
+	}
+	return nil
+}
+
+// patchedReason menjelaskan kenapa dokumen hasil patch tidak bisa dibaca, tanpa nama tipe Go
+func patchedReason(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return "patched document must be an object"
+		}
+		return fmt.Sprintf("field %q has the wrong type", typeErr.Field)
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Sprintf("field %s cannot be changed", field)
+	}
+	return "patched document is invalid"
+}
@@ -2,163 +2,397 @@
 package controllers
 
 import (
-	"learn_project/database"
+	"context"
+	"errors"
+	"learn_project/listing"
 	"learn_project/models"
+	"learn_project/patch"
+	"learn_project/repository"
 	"learn_project/utils"
-
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
-// Struct untuk request body CreateProduct
-type CreateProductInput struct {
-    Name        string  `json:"name" validate:"required"`
-    Description string  `json:"description"`
-    Price       float64 `json:"price" validate:"required,min=0"`
+// ProductInput adalah field produk yang bisa ditulis client: body CreateProduct dan
+// PUT (penggantian penuh, field yang tidak dikirim dikosongkan), sekaligus dokumen
+// yang di-patch oleh PATCH. Stok tidak termasuk, stok diubah lewat movement.
+// Status kosong berarti draft saat create dan status tersimpan saat PUT, supaya PUT
+// tanpa status tidak menarik produk dari katalog publik.
+type ProductInput struct {
+	Name        *string     `json:"name"` // wajib
+	Description string      `json:"description"`
+	Price       *float64    `json:"price"` // wajib, 0 untuk produk gratis
+	SKU         *string     `json:"sku"`   // null atau "" berarti tanpa SKU
+	CategoryIDs []uuid.UUID `json:"category_ids"`
+	// Option varian, mis. [{"name": "size", "values": ["S", "M"]}]
+	Options           []models.ProductOption `json:"options"`
+	LowStockThreshold *int64                 `json:"low_stock_threshold"` // null = tanpa notifikasi
+	Status            models.ProductStatus   `json:"status"`              // draft, published atau archived
+	PublishAt         *time.Time             `json:"publish_at"`          // jadwal tayang, null = langsung saat published
 }
 
-// Struct untuk request body UpdateProduct
-type UpdateProductInput struct {
-    Name        string  `json:"name"`
-    Description string  `json:"description"`
-    Price       float64 `json:"price" validate:"min=0"`
+// CreateProduct creates a new product
+func (h *Handler) CreateProduct(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var input ProductInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+
+	product := models.Product{SellerID: &user.ID}
+	if err := h.applyProductInput(c.UserContext(), &product, input); err != nil {
+		return err
+	}
+
+	err = h.Products.Create(c.UserContext(), &product, &models.ProductRevision{UserID: &user.ID})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return utils.ErrSKUInUse
+	}
+	if err != nil {
+		return utils.ErrProductCreate.Wrap(err)
+	}
+
+	setETag(c, product.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgProductCreated, productData(&product))
 }
 
-// CreateProduct creates a new product
-func CreateProduct(c *fiber.Ctx) error {
-    var input CreateProductInput
-    if err := c.BodyParser(&input); err != nil {
-        return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid input", nil)
-    }
+func (h *Handler) GetProducts(c *fiber.Ctx) error {
+	return h.listProducts(c, repository.ProductFilter{})
+}
 
-    if input.Name == "" || input.Price <= 0 {
-        return utils.ResponseError(c, fiber.StatusBadRequest, "Name and price are required", nil)
-    }
+// listProducts mengirim satu halaman produk yang cocok dengan filter dan query string
+func (h *Handler) listProducts(c *fiber.Ctx, filter repository.ProductFilter) error {
+	// ?status=draft|published|archived
+	if status := models.ProductStatus(c.Query("status")); status != "" {
+		if !status.Valid() {
+			return utils.ErrInvalidFilter.WithData(fiber.Map{"param": "status", "reason": "unknown status"})
+		}
+		filter.Status = status
+	}
 
-    product := models.Product{
-        Name:        input.Name,
-        Description: input.Description,
-        Price:       input.Price,
-    }
+	q, page, err := h.findProducts(c, filter)
+	if err != nil {
+		return err
+	}
 
-    if err := database.DB.Create(&product).Error; err != nil {
-        return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not create product", nil)
-    }
+	// Return the response with pagination details
+	return utils.ResponseSuccessManyData(c, utils.MsgProductsRetrieved, page.Items, q.Page, q.Limit, int(page.Count), page.NextCursor)
+}
 
-    return utils.ResponseSuccessOneData(c, "Product created successfully", fiber.Map{
-        "id":          product.ID,
-        "name":        product.Name,
-        "description": product.Description,
-        "price":       product.Price,
-    })
+// findProducts mengambil satu halaman produk sesuai filter dan query string
+func (h *Handler) findProducts(c *fiber.Ctx, filter repository.ProductFilter) (listing.Query, listing.Page[models.Product], error) {
+	// Sort, filter, search dan pagination (?cursor= atau ?page=), lihat repository.ProductListSpec
+	q, err := h.listQuery(c, repository.ProductListSpec)
+	if err != nil {
+		return q, listing.Page[models.Product]{}, err
+	}
+
+	// ?category=<id atau slug> termasuk semua subkategorinya
+	if ref := c.Query("category"); ref != "" {
+		category, err := h.findCategory(c.UserContext(), ref)
+		if errors.Is(err, utils.ErrCategoryNotFound) {
+			return q, listing.Page[models.Product]{}, utils.ErrInvalidFilter.WithData(fiber.Map{"param": "category", "reason": "unknown category"})
+		}
+		if err != nil {
+			return q, listing.Page[models.Product]{}, err
+		}
+		filter.CategoryID = &category.ID
+	}
+
+	page, err := h.Products.List(c.UserContext(), q, filter)
+	if err != nil {
+		return q, page, utils.ErrProductList.Wrap(err)
+	}
+	return q, page, nil
 }
 
-func GetProducts(c *fiber.Ctx) error {
-	// Parse query parameters for pagination
-	page, _ := strconv.Atoi(c.Query("page", "1"))    // Default page is 1
-	limit, _ := strconv.Atoi(c.Query("limit", "10")) // Default limit is 10
+// GetProduct fetches a single product by ID. If-None-Match dengan ETag yang masih
+// berlaku dijawab 304 tanpa body.
+func (h *Handler) GetProduct(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	if notModified(c, product.Version) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 
-	// Get the search term from query parameters
-	search := c.Query("search", "") // Default is an empty string
+	variants, err := h.productVariants(c.UserContext(), product)
+	if err != nil {
+		return err
+	}
 
-	// Calculate offset
-	offset := (page - 1) * limit
+	images, err := h.productImages(c.UserContext(), product.ID)
+	if err != nil {
+		return err
+	}
 
-	// Fetch products from the database with pagination and search
-	var products []models.Product
-	query := database.DB.Offset(offset).Limit(limit)
+	data := productData(product)
+	data["images"] = images
+	data["variants"] = variants
+	data["price_range"] = priceRange(product, variants)
+	return utils.ResponseSuccessOneData(c, utils.MsgProductRetrieved, data)
+}
 
-	// Add search filter if search term is provided
-	if search != "" {
-			search = strings.ToLower(search) // Convert search term to lowercase for case-insensitive search
-			query = query.Where("LOWER(name) LIKE ?", "%"+search+"%")
+// UpdateProduct mengganti seluruh field produk yang bisa ditulis (PUT). Field yang
+// tidak dikirim dikosongkan; untuk mengubah sebagian field pakai PatchProduct.
+// If-Match dengan ETag dari GET wajib dikirim supaya perubahan orang lain tidak tertimpa.
+func (h *Handler) UpdateProduct(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	user, err := h.authorizeProduct(c, product)
+	if err != nil {
+		return err
+	}
+	version, err := checkIfMatch(c, product.Version, true)
+	if err != nil {
+		return err
 	}
 
-	if err := query.Find(&products).Error; err != nil {
-			return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not fetch products", nil)
+	var input ProductInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
 	}
+	return h.replaceProduct(c, user, product, version, input)
+}
 
-	// Get the total count of products (with search filter if applicable)
-	var count int64
-	countQuery := database.DB.Model(&models.Product{})
+// PatchProduct mengubah sebagian field produk dengan JSON Merge Patch (null menghapus
+// nilai field) atau JSON Patch, lalu memvalidasi hasilnya sama seperti PUT.
+// If-Match wajib, sama seperti PUT.
+func (h *Handler) PatchProduct(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderAcceptPatch, patch.Accepted)
 
-	if search != "" {
-			countQuery = countQuery.Where("LOWER(name) LIKE ?", "%"+search+"%")
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	user, err := h.authorizeProduct(c, product)
+	if err != nil {
+		return err
+	}
+	version, err := checkIfMatch(c, product.Version, true)
+	if err != nil {
+		return err
 	}
 
-	if err := countQuery.Count(&count).Error; err != nil {
-			return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not fetch product count", nil)
+	var input ProductInput
+	if err := applyPatch(c, productInput(product), &input); err != nil {
+		return err
 	}
+	return h.replaceProduct(c, user, product, version, input)
+}
 
-	// Return the response with pagination details
-	return utils.ResponseSuccessManyData(c, "Products retrieved successfully", products, page, limit, int(count))
-}
-
-
-// GetProduct fetches a single product by ID
-func GetProduct(c *fiber.Ctx) error {
-    id := c.Params("id")
-    var product models.Product
-    if err := database.DB.Where("id = ?", id).First(&product).Error; err != nil {
-        return utils.ResponseError(c, fiber.StatusNotFound, "Product not found", nil)
-    }
-
-    return utils.ResponseSuccessOneData(c, "Product retrieved successfully", fiber.Map{
-        "id":          product.ID,
-        "name":        product.Name,
-        "description": product.Description,
-        "price":       product.Price,
-    })
-}
-
-// UpdateProduct updates a product by ID
-func UpdateProduct(c *fiber.Ctx) error {
-    id := c.Params("id")
-    var product models.Product
-    if err := database.DB.Where("id = ?", id).First(&product).Error; err != nil {
-        return utils.ResponseError(c, fiber.StatusNotFound, "Product not found", nil)
-    }
-
-    var input UpdateProductInput
-    if err := c.BodyParser(&input); err != nil {
-        return utils.ResponseError(c, fiber.StatusBadRequest, "Invalid input", nil)
-    }
-
-    if input.Name != "" {
-        product.Name = input.Name
-    }
-    if input.Description != "" {
-        product.Description = input.Description
-    }
-    if input.Price > 0 {
-        product.Price = input.Price
-    }
-
-    if err := database.DB.Save(&product).Error; err != nil {
-        return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not update product", nil)
-    }
-
-    return utils.ResponseSuccessOneData(c, "Product updated successfully", fiber.Map{
-        "id":          product.ID,
-        "name":        product.Name,
-        "description": product.Description,
-        "price":       product.Price,
-    })
-}
-
-// DeleteProduct deletes a product by ID
-func DeleteProduct(c *fiber.Ctx) error {
-    id := c.Params("id")
-    var product models.Product
-    if err := database.DB.Where("id = ?", id).First(&product).Error; err != nil {
-        return utils.ResponseError(c, fiber.StatusNotFound, "Product not found", nil)
-    }
-
-    if err := database.DB.Delete(&product).Error; err != nil {
-        return utils.ResponseError(c, fiber.StatusInternalServerError, "Could not delete product", nil)
-    }
-
-    return utils.ResponseSuccessOneData(c, "Product deleted successfully", nil)
-}
\ No newline at end of file
+// replaceProduct menerapkan input ke produk yang sudah ada lalu menyimpannya kalau
+// version-nya masih sama (hasil checkIfMatch)
+func (h *Handler) replaceProduct(c *fiber.Ctx, user *models.User, product *models.Product, version int64, input ProductInput) error {
+	before := *product
+	if err := h.applyProductInput(c.UserContext(), product, input); err != nil {
+		return err
+	}
+	// Varian yang sudah ada harus tetap cocok dengan option yang baru
+	if err := h.checkVariantOptions(c.UserContext(), product.ID, product.Options); err != nil {
+		return err
+	}
+
+	// Produk masih bisa diubah request lain setelah If-Match dicek; repository
+	// membandingkan version lagi di dalam transaksi
+	product.Version = version
+	err := h.Products.Update(c.UserContext(), product, &models.ProductRevision{UserID: &user.ID})
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return utils.ErrSKUInUse
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case err != nil:
+		return utils.ErrProductUpdate.Wrap(err)
+	}
+	h.publishStockEvents(c.UserContext(), &before, product)
+
+	setETag(c, product.Version)
+	return utils.ResponseSuccessOneData(c, utils.MsgProductUpdated, productData(product))
+}
+
+// DeleteProduct memindahkan produk ke trash (If-Match wajib); admin bisa memulihkannya
+// lewat RestoreProduct
+func (h *Handler) DeleteProduct(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	user, err := h.authorizeProduct(c, product)
+	if err != nil {
+		return err
+	}
+	version, err := checkIfMatch(c, product.Version, true)
+	if err != nil {
+		return err
+	}
+
+	err = h.Products.Delete(c.UserContext(), product.ID, version, &models.ProductRevision{UserID: &user.ID})
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return utils.ErrPreconditionFailed
+	}
+	if err != nil {
+		return utils.ErrProductDelete.Wrap(err)
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgProductDeleted, nil)
+}
+
+// findProduct mengambil produk dari path parameter :id
+func (h *Handler) findProduct(c *fiber.Ctx) (*models.Product, error) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return nil, utils.ErrProductNotFound
+	}
+
+	product, err := h.Products.FindByID(c.UserContext(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, utils.ErrProductNotFound
+	}
+	if err != nil {
+		return nil, utils.ErrProductList.Wrap(err)
+	}
+	return product, nil
+}
+
+// authorizeProduct mengembalikan user yang sedang login kalau boleh mengubah produk
+// (seller pemiliknya atau admin), selain itu 403
+func (h *Handler) authorizeProduct(c *fiber.Ctx, product *models.Product) (*models.User, error) {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return nil, err
+	}
+	if !product.EditableBy(user) {
+		return nil, utils.ErrNotProductOwner
+	}
+	return user, nil
+}
+
+// applyProductInput memvalidasi input lalu mengisi field produk
+func (h *Handler) applyProductInput(ctx context.Context, product *models.Product, input ProductInput) error {
+	if input.Name == nil || *input.Name == "" || input.Price == nil {
+		return utils.ErrProductNameAndPriceRequired
+	}
+	if *input.Price < 0 {
+		return utils.ErrInvalidPrice
+	}
+	if input.LowStockThreshold != nil && *input.LowStockThreshold < 0 {
+		return utils.ErrInvalidStockThreshold
+	}
+	status := input.Status
+	if status == "" {
+		status = product.Status
+	}
+	if status == "" {
+		status = models.ProductDraft
+	}
+	if !status.Valid() {
+		return utils.ErrInvalidProductStatus
+	}
+	// Jadwal tayang disimpan dalam UTC dengan presisi detik
+	var publishAt *time.Time
+	if input.PublishAt != nil {
+		at := input.PublishAt.UTC().Truncate(time.Second)
+		publishAt = &at
+	}
+
+	categories, err := h.productCategories(ctx, input.CategoryIDs)
+	if err != nil {
+		return err
+	}
+
+	options, err := productOptions(input.Options)
+	if err != nil {
+		return err
+	}
+
+	var sku *string
+	if input.SKU != nil && strings.TrimSpace(*input.SKU) != "" {
+		if sku, err = productSKU(*input.SKU); err != nil {
+			return err
+		}
+	}
+
+	product.Name = *input.Name
+	product.Description = input.Description
+	product.Price = *input.Price
+	product.SKU = sku
+	product.Categories = categories
+	product.Options = options
+	product.LowStockThreshold = input.LowStockThreshold
+	product.Status = status
+	product.PublishAt = publishAt
+	return nil
+}
+
+// productInput adalah dokumen yang di-patch oleh PatchProduct
+func productInput(product *models.Product) ProductInput {
+	ids := make([]uuid.UUID, len(product.Categories))
+	for i, category := range product.Categories {
+		ids[i] = category.ID
+	}
+	options := []models.ProductOption(product.Options)
+	if options == nil {
+		options = []models.ProductOption{}
+	}
+	return ProductInput{
+		Name:              &product.Name,
+		Description:       product.Description,
+		Price:             &product.Price,
+		SKU:               product.SKU,
+		CategoryIDs:       ids,
+		Options:           options,
+		LowStockThreshold: product.LowStockThreshold,
+		Status:            product.Status,
+		PublishAt:         product.PublishAt,
+	}
+}
+
+// productSKU menormalkan dan memvalidasi SKU produk, formatnya sama dengan SKU varian
+func productSKU(raw string) (*string, error) {
+	sku := normalizeSKU(raw)
+	if !skuPattern.MatchString(sku) {
+		return nil, utils.ErrInvalidSKU
+	}
+	return &sku, nil
+}
+
+// productData adalah bentuk response satu produk
+func productData(product *models.Product) fiber.Map {
+	categories := product.Categories
+	if categories == nil {
+		categories = []models.Category{}
+	}
+	options := product.Options
+	if options == nil {
+		options = models.ProductOptions{}
+	}
+	return fiber.Map{
+		"id":          product.ID,
+		"name":        product.Name,
+		"description": product.Description,
+		"price":       product.Price,
+		"sku":         product.SKU,
+		"seller_id":   product.SellerID,
+		"categories":  categories,
+		"options":     options,
+		"version":     product.Version,
+		"status":      product.Status,
+		"publish_at":  product.PublishAt,
+
+		"stock":               product.Stock,
+		"reserved":            product.Reserved,
+		"available":           product.Available(),
+		"low_stock_threshold": product.LowStockThreshold,
+	}
+}
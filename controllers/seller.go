@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxStoreNameLength = 100
+
+// SellerInput adalah body PUT /api/me/seller. Slug dibuat dari nama toko kalau kosong.
+type SellerInput struct {
+	StoreName string `json:"store_name"`
+	Slug      string `json:"slug"`
+}
+
+// GetMySeller mengembalikan profil toko user yang sedang login
+func (h *Handler) GetMySeller(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	seller, err := h.findSeller(c.UserContext(), user.ID.String())
+	if err != nil {
+		return err
+	}
+	return utils.ResponseSuccessOneData(c, utils.MsgSellerRetrieved, seller)
+}
+
+// SaveMySeller membuat profil toko user yang sedang login, atau menggantinya kalau sudah ada
+func (h *Handler) SaveMySeller(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	var input SellerInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+	name := strings.TrimSpace(input.StoreName)
+	if name == "" || utf8.RuneCountInString(name) > maxStoreNameLength {
+		return utils.ErrStoreNameRequired
+	}
+	slug, err := makeSlug(input.Slug, name)
+	if err != nil {
+		return err
+	}
+
+	seller, err := h.Sellers.FindByID(c.UserContext(), user.ID)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		seller = &models.Seller{ID: user.ID, StoreName: name, Slug: slug}
+		err = h.Sellers.Create(c.UserContext(), seller)
+	case err != nil:
+		return utils.ErrSellerSave.Wrap(err)
+	default:
+		seller.StoreName, seller.Slug = name, slug
+		err = h.Sellers.Update(c.UserContext(), seller)
+	}
+	if errors.Is(err, repository.ErrDuplicate) {
+		return utils.ErrSlugInUse
+	}
+	if err != nil {
+		return utils.ErrSellerSave.Wrap(err)
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgSellerSaved, seller)
+}
+
+// GetMyProducts mengembalikan produk milik user yang sedang login, dengan sort,
+// filter dan pagination yang sama seperti GET /api/products
+func (h *Handler) GetMyProducts(c *fiber.Ctx) error {
+	user, err := h.currentUser(c)
+	if err != nil {
+		return err
+	}
+	return h.listProducts(c, repository.ProductFilter{SellerID: &user.ID})
+}
+
+// GetSeller mengembalikan profil toko berdasarkan ID atau slug (publik)
+func (h *Handler) GetSeller(c *fiber.Ctx) error {
+	seller, err := h.findSeller(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return utils.ResponseSuccessOneData(c, utils.MsgSellerRetrieved, seller)
+}
+
+// GetSellerProducts mengembalikan produk sebuah toko yang tampil di katalog publik,
+// dalam bentuk yang sama dengan GET /catalog/products. Hanya user yang sudah membuat
+// profil toko yang punya halaman publik.
+func (h *Handler) GetSellerProducts(c *fiber.Ctx) error {
+	seller, err := h.findSeller(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return h.listCatalog(c, repository.ProductFilter{SellerID: &seller.ID})
+}
+
+// findSeller mencari profil toko berdasarkan ID, atau slug kalau bukan UUID
+func (h *Handler) findSeller(ctx context.Context, ref string) (*models.Seller, error) {
+	var seller *models.Seller
+	var err error
+	if id, parseErr := uuid.Parse(ref); parseErr == nil {
+		seller, err = h.Sellers.FindByID(ctx, id)
+	} else {
+		seller, err = h.Sellers.FindBySlug(ctx, ref)
+	}
+
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, utils.ErrSellerNotFound
+	}
+	if err != nil {
+		return nil, utils.ErrSellerList.Wrap(err)
+	}
+	return seller, nil
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"time"
+
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// catalogCacheControl: katalog publik boleh di-cache browser sebentar dan CDN lebih
+// lama. Perubahan produk (termasuk jadwal tayang yang lewat atau produk yang di-archive)
+// terlihat paling lambat setelah s-maxage; ETag dari middleware etag membuat
+// revalidasi murah.
+const catalogCacheControl = "public, max-age=60, s-maxage=300, stale-while-revalidate=60"
+
+// GetCatalogProducts mengembalikan produk yang tampil di katalog publik (tanpa login),
+// dengan sort, filter dan pagination yang sama seperti GET /api/products
+func (h *Handler) GetCatalogProducts(c *fiber.Ctx) error {
+	return h.listCatalog(c, repository.ProductFilter{})
+}
+
+// GetCatalogProduct mengembalikan satu produk katalog publik beserta varian dan
+// gambarnya. Produk draft, archived atau yang belum tayang dianggap tidak ada.
+func (h *Handler) GetCatalogProduct(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	if !product.IsPublic(time.Now()) {
+		return utils.ErrProductNotFound
+	}
+
+	variants, err := h.productVariants(c.UserContext(), product)
+	if err != nil {
+		return err
+	}
+
+	images, err := h.productImages(c.UserContext(), product.ID)
+	if err != nil {
+		return err
+	}
+
+	data := catalogProductData(product)
+	data["images"] = images
+	data["variants"] = catalogVariants(variants)
+	data["price_range"] = priceRange(product, variants)
+
+	c.Set(fiber.HeaderCacheControl, catalogCacheControl)
+	return utils.ResponseSuccessOneData(c, utils.MsgProductRetrieved, data)
+}
+
+// listCatalog mengirim satu halaman produk yang tampil di katalog publik saat ini
+func (h *Handler) listCatalog(c *fiber.Ctx, filter repository.ProductFilter) error {
+	now := time.Now().UTC()
+	filter.PublicAt = &now
+
+	q, page, err := h.findProducts(c, filter)
+	if err != nil {
+		return err
+	}
+
+	items := make([]fiber.Map, len(page.Items))
+	for i := range page.Items {
+		items[i] = catalogProductData(&page.Items[i])
+	}
+
+	c.Set(fiber.HeaderCacheControl, catalogCacheControl)
+	return utils.ResponseSuccessManyData(c, utils.MsgProductsRetrieved, items, q.Page, q.Limit, int(page.Count), page.NextCursor)
+}
+
+// catalogProductData adalah bentuk produk di katalog publik: tanpa stok, status,
+// version dan field internal lain
+func catalogProductData(product *models.Product) fiber.Map {
+	data := fiber.Map{
+		"id":          product.ID,
+		"name":        product.Name,
+		"description": product.Description,
+		"price":       product.Price,
+		"sku":         product.SKU,
+		"seller_id":   product.SellerID,
+		"categories":  product.Categories,
+		"options":     product.Options,
+		"in_stock":    product.Available() > 0,
+	}
+	if product.Categories == nil {
+		data["categories"] = []models.Category{}
+	}
+	if product.Options == nil {
+		data["options"] = models.ProductOptions{}
+	}
+	// Hanya ada pada hasil pencarian ?q=
+	if product.Highlight != "" {
+		data["highlight"] = product.Highlight
+	}
+	return data
+}
+
+// catalogVariants adalah varian di katalog publik: harga efektif dan ketersediaan, tanpa jumlah stok
+func catalogVariants(variants []models.ProductVariant) []fiber.Map {
+	data := make([]fiber.Map, len(variants))
+	for i, variant := range variants {
+		data[i] = fiber.Map{
+			"id":         variant.ID,
+			"sku":        variant.SKU,
+			"attributes": variant.Attributes,
+			"price":      variant.EffectivePrice,
+			"in_stock":   variant.Stock > 0,
+		}
+	}
+	return data
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"learn_project/models"
+	"learn_project/repository"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Struct untuk request body CreateVariant
+type CreateVariantInput struct {
+	SKU        string            `json:"sku"`
+	Price      *float64          `json:"price"` // kosong = memakai harga produk
+	Stock      int64             `json:"stock"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// Struct untuk request body UpdateVariant, field yang tidak dikirim tidak diubah
+type UpdateVariantInput struct {
+	SKU string `json:"sku"`
+	// null menghapus override sehingga varian kembali memakai harga produk
+	Price      json.RawMessage   `json:"price"`
+	Stock      *int64            `json:"stock"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// skuPattern adalah format SKU setelah dinormalisasi ke huruf besar
+var skuPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9._-]{0,63}$`)
+
+// CreateVariant menambah varian (SKU) ke produk
+func (h *Handler) CreateVariant(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.authorizeProduct(c, product); err != nil {
+		return err
+	}
+	version, err := checkProductMatch(c, product)
+	if err != nil {
+		return err
+	}
+
+	var input CreateVariantInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+
+	variant := models.ProductVariant{
+		ProductID:  product.ID,
+		SKU:        normalizeSKU(input.SKU),
+		Price:      input.Price,
+		Stock:      input.Stock,
+		Attributes: input.Attributes,
+	}
+	if err := h.validateVariant(c.UserContext(), product, &variant); err != nil {
+		return err
+	}
+
+	err = h.Variants.Create(c.UserContext(), &variant, version)
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return utils.ErrSKUInUse
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.ErrProductNotFound
+	case err != nil:
+		return utils.ErrVariantCreate.Wrap(err)
+	}
+
+	variant.EffectivePrice = variant.PriceFor(product)
+	return utils.ResponseSuccessOneData(c, utils.MsgVariantCreated, variant)
+}
+
+// GetVariants mengembalikan semua varian produk
+func (h *Handler) GetVariants(c *fiber.Ctx) error {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return err
+	}
+
+	variants, err := h.productVariants(c.UserContext(), product)
+	if err != nil {
+		return err
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgVariantsRetrieved, variants)
+}
+
+// GetVariant mengembalikan satu varian produk
+func (h *Handler) GetVariant(c *fiber.Ctx) error {
+	product, variant, err := h.findVariant(c)
+	if err != nil {
+		return err
+	}
+
+	variant.EffectivePrice = variant.PriceFor(product)
+	return utils.ResponseSuccessOneData(c, utils.MsgVariantRetrieved, variant)
+}
+
+// UpdateVariant mengubah SKU, harga, stok atau atribut varian
+func (h *Handler) UpdateVariant(c *fiber.Ctx) error {
+	product, variant, err := h.findVariant(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.authorizeProduct(c, product); err != nil {
+		return err
+	}
+	version, err := checkProductMatch(c, product)
+	if err != nil {
+		return err
+	}
+
+	var input UpdateVariantInput
+	if err := c.BodyParser(&input); err != nil {
+		return utils.ErrInvalidInput
+	}
+
+	if input.SKU != "" {
+		variant.SKU = normalizeSKU(input.SKU)
+	}
+	if input.Price != nil {
+		var price *float64
+		if err := json.Unmarshal(input.Price, &price); err != nil {
+			return utils.ErrInvalidInput
+		}
+		variant.Price = price
+	}
+	if input.Stock != nil {
+		variant.Stock = *input.Stock
+	}
+	if input.Attributes != nil {
+		variant.Attributes = input.Attributes
+	}
+	if err := h.validateVariant(c.UserContext(), product, variant); err != nil {
+		return err
+	}
+
+	err = h.Variants.Update(c.UserContext(), variant, version)
+	switch {
+	case errors.Is(err, repository.ErrDuplicate):
+		return utils.ErrSKUInUse
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.ErrVariantNotFound
+	case err != nil:
+		return utils.ErrVariantUpdate.Wrap(err)
+	}
+
+	variant.EffectivePrice = variant.PriceFor(product)
+	return utils.ResponseSuccessOneData(c, utils.MsgVariantUpdated, variant)
+}
+
+// DeleteVariant menghapus varian produk
+func (h *Handler) DeleteVariant(c *fiber.Ctx) error {
+	product, variant, err := h.findVariant(c)
+	if err != nil {
+		return err
+	}
+	if _, err := h.authorizeProduct(c, product); err != nil {
+		return err
+	}
+	version, err := checkProductMatch(c, product)
+	if err != nil {
+		return err
+	}
+
+	err = h.Variants.Delete(c.UserContext(), product.ID, variant.ID, version)
+	switch {
+	case errors.Is(err, repository.ErrVersionConflict):
+		return utils.ErrPreconditionFailed
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.ErrVariantNotFound
+	case err != nil:
+		return utils.ErrVariantDelete.Wrap(err)
+	}
+
+	return utils.ResponseSuccessOneData(c, utils.MsgVariantDeleted, nil)
+}
+
+// findVariant mengambil produk :id dan variannya :variantID. Varian milik produk
+// lain dianggap tidak ada.
+func (h *Handler) findVariant(c *fiber.Ctx) (*models.Product, *models.ProductVariant, error) {
+	product, err := h.findProduct(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id, ok := paramID(c, "variantID")
+	if !ok {
+		return nil, nil, utils.ErrVariantNotFound
+	}
+
+	variant, err := h.Variants.FindByID(c.UserContext(), id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && variant.ProductID != product.ID) {
+		return nil, nil, utils.ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, nil, utils.ErrVariantList.Wrap(err)
+	}
+	return product, variant, nil
+}
+
+// productVariants mengambil varian produk lengkap dengan EffectivePrice
+func (h *Handler) productVariants(ctx context.Context, product *models.Product) ([]models.ProductVariant, error) {
+	variants, err := h.Variants.ListByProduct(ctx, product.ID)
+	if err != nil {
+		return nil, utils.ErrVariantList.Wrap(err)
+	}
+	for i := range variants {
+		variants[i].EffectivePrice = variants[i].PriceFor(product)
+	}
+	return variants, nil
+}
+
+// validateVariant mengecek SKU, harga, stok dan atribut, termasuk kombinasi atribut
+// yang sudah dipakai varian lain di produk yang sama
+func (h *Handler) validateVariant(ctx context.Context, product *models.Product, variant *models.ProductVariant) error {
+	if !skuPattern.MatchString(variant.SKU) {
+		return utils.ErrInvalidSKU
+	}
+	if (variant.Price != nil && *variant.Price <= 0) || variant.Stock < 0 {
+		return utils.ErrInvalidVariant
+	}
+	if variant.Attributes == nil {
+		variant.Attributes = models.VariantAttributes{}
+	}
+	if !validAttributes(product.Options, variant.Attributes) {
+		return utils.ErrInvalidVariantAttributes.WithData(fiber.Map{"options": product.Options})
+	}
+
+	variants, err := h.Variants.ListByProduct(ctx, product.ID)
+	if err != nil {
+		return utils.ErrVariantList.Wrap(err)
+	}
+	for _, other := range variants {
+		if other.ID != variant.ID && maps.Equal(other.Attributes, variant.Attributes) {
+			return utils.ErrDuplicateVariant.WithData(fiber.Map{"sku": other.SKU})
+		}
+	}
+	return nil
+}
+
+// checkVariantOptions memastikan varian yang sudah ada tetap valid kalau option produk diubah
+func (h *Handler) checkVariantOptions(ctx context.Context, productID uuid.UUID, options models.ProductOptions) error {
+	variants, err := h.Variants.ListByProduct(ctx, productID)
+	if err != nil {
+		return utils.ErrVariantList.Wrap(err)
+	}
+
+	var skus []string
+	for _, variant := range variants {
+		if !validAttributes(options, variant.Attributes) {
+			skus = append(skus, variant.SKU)
+		}
+	}
+	if len(skus) > 0 {
+		return utils.ErrOptionsInUse.WithData(fiber.Map{"skus": skus})
+	}
+	return nil
+}
+
+// validAttributes: setiap option harus punya tepat satu nilai yang diizinkan, tanpa atribut lain
+func validAttributes(options models.ProductOptions, attributes models.VariantAttributes) bool {
+	if len(attributes) != len(options) {
+		return false
+	}
+	for _, option := range options {
+		value, ok := attributes[option.Name]
+		if !ok || !slices.Contains(option.Values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// productOptions merapikan option dari request: nama dan nilai di-trim, tidak boleh
+// kosong, nama unik (tanpa membedakan huruf besar/kecil) dan nilai unik per option
+func productOptions(input []models.ProductOption) (models.ProductOptions, error) {
+	options := models.ProductOptions{}
+	names := map[string]bool{}
+	for _, option := range input {
+		name := strings.TrimSpace(option.Name)
+		if name == "" || names[strings.ToLower(name)] || len(option.Values) == 0 {
+			return nil, utils.ErrInvalidProductOptions
+		}
+		names[strings.ToLower(name)] = true
+
+		values := make([]string, 0, len(option.Values))
+		for _, value := range option.Values {
+			value = strings.TrimSpace(value)
+			if value == "" || slices.Contains(values, value) {
+				return nil, utils.ErrInvalidProductOptions
+			}
+			values = append(values, value)
+		}
+		options = append(options, models.ProductOption{Name: name, Values: values})
+	}
+	return options, nil
+}
+
+// priceRange adalah harga termurah dan termahal dari semua varian,
+// atau harga produk kalau produk belum punya varian
+func priceRange(product *models.Product, variants []models.ProductVariant) fiber.Map {
+	low, high := product.Price, product.Price
+	for i, variant := range variants {
+		price := variant.PriceFor(product)
+		if i == 0 || price < low {
+			low = price
+		}
+		if i == 0 || price > high {
+			high = price
+		}
+	}
+	return fiber.Map{"min": low, "max": high}
+}
+
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
@@ -2,52 +2,248 @@ package database
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"learn_project/config"
+	"learn_project/logging"
+	"learn_project/metrics"
 	"learn_project/models"
+	"learn_project/tracing"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-
 var DB *gorm.DB
 
-func Connect() {
+func Connect(cfg config.DatabaseConfig, logCfg config.LogConfig) {
+	var err error
+	DB, err = openWithRetry(cfg, logCfg)
+	if err != nil {
+		slog.Error("❌ Gagal koneksi ke database", "error", err)
+		os.Exit(1)
+	}
 
-		dbHost := os.Getenv("DB_HOST")
-		dbPort := os.Getenv("DB_PORT")
-		dbUser := os.Getenv("DB_USER")
-		dbPassword := os.Getenv("DB_PASSWORD")
-		dbName := os.Getenv("DB_NAME")
-    // Create the DSN (Data Source Name)
-    // Create DSN (Data Source Name)
-	dsn := fmt.Sprintf("host=%s user=%s dbname=%s password=%s port=%s sslmode=disable",
-	dbHost, dbUser, dbName, dbPassword, dbPort)
+	slog.Info("✅ Berhasil koneksi ke database!", "driver", cfg.Driver, "host", cfg.Host, "database", cfg.Name)
 
-	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// Statistik connection pool untuk Prometheus
+	if sqlDB, err := DB.DB(); err == nil {
+		if err := metrics.RegisterDBStats(sqlDB, cfg.Name); err != nil {
+			slog.Warn("Gagal mendaftarkan metric connection pool", "error", err)
+		}
+	}
+
+	// AutoMigrate hanya untuk development lokal, selain itu pakai `migrate up`
+	if cfg.AutoMigrate {
+		autoMigrate()
+	}
+}
+
+// openWithRetry mencoba Open dengan backoff eksponensial sampai cfg.ConnectTimeout habis,
+// supaya aplikasi tidak crash-loop kalau database baru siap beberapa detik kemudian
+func openWithRetry(cfg config.DatabaseConfig, logCfg config.LogConfig) (*gorm.DB, error) {
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	backoff := cfg.ConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		// Satu percobaan dibatasi sisa waktu (connect_timeout), supaya host yang tidak
+		// menjawab tidak menahan start sampai timeout TCP kernel
+		attemptCfg := cfg
+		if remaining := time.Until(deadline); remaining > 0 {
+			attemptCfg.ConnectTimeout = remaining
+		}
+		db, err := Open(attemptCfg, logCfg)
+		if err == nil {
+			return db, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("menyerah setelah %d percobaan: %w", attempt, err)
+		}
+
+		slog.Warn("Database belum siap, mencoba lagi", "attempt", attempt, "retry_in", backoff.String(), "error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, cfg.ConnectMaxBackoff)
+	}
+}
+
+// Open membuka koneksi sesuai cfg.Driver dan memasang plugin metrics dan tracing.
+// Dipakai Connect dan juga test integrasi (sqlite :memory:).
+func Open(cfg config.DatabaseConfig, logCfg config.LogConfig) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.Driver {
+	case "postgres":
+		dialector = postgres.Open(postgresDSN(cfg))
+	case "sqlite":
+		dialector = sqlite.Open(sqliteDSN(cfg))
+	default:
+		return nil, fmt.Errorf("driver database %q tidak dikenal", cfg.Driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logging.NewGormLogger(logCfg.SlowQueryThreshold),
+		// Error driver (mis. unique violation) diterjemahkan ke gorm.ErrDuplicatedKey
+		TranslateError: true,
+		PrepareStmt:    cfg.PrepareStmt,
+	})
+	if err != nil {
+		// gorm.Open tetap membuat pool walaupun ping gagal, tutup supaya retry tidak bocor
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("❌ Gagal koneksi ke database:", err)
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	if cfg.Driver == "sqlite" {
+		// SQLite hanya mengizinkan satu writer, dan setiap koneksi :memory: adalah
+		// database terpisah, jadi pool dibatasi satu koneksi yang tidak pernah ditutup
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+
+	// Durasi query untuk Prometheus, span per query untuk tracing
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("gagal memasang plugin metrics: %w", err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("gagal memasang plugin tracing: %w", err)
+	}
+	return db, nil
+}
+
+// postgresDSN memakai DATABASE_URL kalau ada, selain itu menyusun DSN key=value
+// dari field koneksi dan opsi TLS. connect_timeout (detik, dibulatkan ke atas) membatasi
+// satu percobaan koneksi, statement_timeout dikirim sebagai runtime parameter.
+func postgresDSN(cfg config.DatabaseConfig) string {
+	var extra [][2]string
+	if cfg.ConnectTimeout > 0 {
+		seconds := int64(math.Ceil(cfg.ConnectTimeout.Seconds()))
+		extra = append(extra, [2]string{"connect_timeout", strconv.FormatInt(seconds, 10)})
+	}
+	if cfg.StatementTimeout > 0 {
+		extra = append(extra, [2]string{"statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)})
+	}
+
+	if cfg.URL != "" {
+		return withDSNParams(cfg.URL, extra)
+	}
+
+	params := [][2]string{
+		{"host", cfg.Host},
+		{"port", cfg.Port},
+		{"user", cfg.User},
+		{"password", cfg.Password},
+		{"dbname", cfg.Name},
+		{"sslmode", cfg.SSLMode},
+		{"sslrootcert", cfg.SSLRootCert},
+		{"sslcert", cfg.SSLCert},
+		{"sslkey", cfg.SSLKey},
+	}
+	params = append(params, extra...)
+
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		if p[1] != "" {
+			parts = append(parts, p[0]+"="+quoteDSNValue(p[1]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// withDSNParams menambahkan params ke DATABASE_URL (bentuk URL maupun key=value),
+// kecuali yang sudah diisi sendiri di DATABASE_URL
+func withDSNParams(dsn string, params [][2]string) string {
+	if len(params) == 0 {
+		return dsn
+	}
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		query := u.Query()
+		for _, p := range params {
+			if !query.Has(p[0]) {
+				query.Set(p[0], p[1])
+			}
+		}
+		u.RawQuery = query.Encode()
+		return u.String()
+	}
+	for _, p := range params {
+		if !strings.Contains(dsn, p[0]+"=") {
+			dsn += " " + p[0] + "=" + p[1]
+		}
 	}
+	return dsn
+}
 
-	log.Println("✅ Berhasil koneksi ke database!")
+// quoteDSNValue mengutip nilai yang berisi spasi, kutip atau backslash (mis. password)
+func quoteDSNValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
 
-	// Jalankan migrasi otomatis
-	autoMigrate()
+// sqliteDSN memakai DATABASE_URL atau DB_NAME sebagai path file (atau :memory:),
+// dengan foreign key aktif dan busy timeout supaya tidak langsung gagal saat terkunci
+func sqliteDSN(cfg config.DatabaseConfig) string {
+	dsn := cfg.URL
+	if dsn == "" {
+		dsn = cfg.Name
+	}
 
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
 }
 
-// Fungsi untuk migrasi otomatis
+// Close menutup connection pool, dipanggil saat graceful shutdown
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Fungsi untuk migrasi otomatis (khusus development, lihat DB_AUTO_MIGRATE)
 func autoMigrate() {
 	err := DB.AutoMigrate(
 		&models.User{},
+		&models.Category{},
 		&models.Product{},
 		&models.Bank{},
-)
+		&models.InventoryMovement{},
+		&models.ProductVariant{},
+		&models.ProductImage{},
+		&models.ProductRevision{},
+		&models.Seller{},
+	)
 	if err != nil {
-		log.Fatal("❌ Gagal melakukan migrasi:", err)
+		slog.Error("❌ Gagal melakukan migrasi", "error", err)
+		os.Exit(1)
 	}
-	log.Println("✅ Migrasi berhasil!")
-}
\ No newline at end of file
+	slog.Info("✅ Migrasi berhasil!")
+}
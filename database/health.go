@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"learn_project/health"
+
+	"gorm.io/gorm"
+)
+
+// PingCheck adalah readiness check yang mem-ping connection pool database
+func PingCheck(db *gorm.DB) health.Check {
+	return health.Check{
+		Name: "database",
+		Run: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// MigrationsCheck adalah readiness check yang gagal kalau masih ada migrasi yang belum dijalankan
+func MigrationsCheck(migrator *Migrator) health.Check {
+	return health.Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d migration(s) pending", len(pending))
+			}
+			return nil
+		},
+	}
+}
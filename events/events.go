@@ -0,0 +1,46 @@
+// Package events mengirim event domain (mis. stok produk menipis) ke sistem lain.
+// Controller memanggil Publisher.Publish setelah perubahan berhasil disimpan;
+// pengiriman ke webhook berjalan di background supaya tidak menahan request.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipe event yang dikirim aplikasi
+const (
+	// ProductStockLow: stok tersedia (stock - reserved) turun sampai low_stock_threshold atau di bawahnya
+	ProductStockLow = "product.stock_low"
+	// ProductStockRestored: stok tersedia naik lagi di atas low_stock_threshold
+	ProductStockRestored = "product.stock_restored"
+)
+
+// Event adalah payload yang dikirim ke webhook
+type Event struct {
+	ID         uuid.UUID `json:"id"` // untuk deduplikasi di penerima, tetap sama saat retry
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// New membuat event baru dengan ID dan waktu sekarang
+func New(eventType string, data any) Event {
+	return Event{ID: uuid.New(), Type: eventType, OccurredAt: time.Now().UTC(), Data: data}
+}
+
+// Publisher mengirim event. Publish tidak boleh memblok lama dan tidak mengembalikan
+// error: kegagalan pengiriman dicatat oleh implementasinya.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// LogPublisher hanya mencatat event ke log, dipakai kalau WEBHOOK_URL kosong
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, event Event) {
+	slog.InfoContext(ctx, "event", "event_id", event.ID, "type", event.Type, "data", event.Data)
+}
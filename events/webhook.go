@@ -0,0 +1,144 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"learn_project/config"
+	"learn_project/metrics"
+)
+
+// Header yang dikirim bersama setiap event. Penerima memverifikasi signature dengan
+// HMAC-SHA256(secret, timestamp + "." + body) dan sebaiknya menolak timestamp yang terlalu lama.
+const (
+	HeaderEventID   = "X-Event-ID"
+	HeaderEventType = "X-Event-Type"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// retryBackoff adalah jeda sebelum percobaan ulang pertama, dua kali lipat tiap gagal
+var retryBackoff = time.Second
+
+// Webhook mengirim event sebagai POST JSON ke satu URL. Event diantre dan dikirim
+// berurutan oleh satu goroutine; kalau antrean penuh event dibuang (dicatat di log dan metric).
+type Webhook struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+	queue  chan Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewWebhook membuat Webhook dan menjalankan goroutine pengirimnya. Panggil Close saat shutdown.
+func NewWebhook(cfg config.WebhookConfig) *Webhook {
+	w := &Webhook{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		queue:  make(chan Event, cfg.QueueSize),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *Webhook) Publish(ctx context.Context, event Event) {
+	select {
+	case w.queue <- event:
+	default:
+		metrics.WebhookDeliveriesTotal.WithLabelValues(event.Type, "dropped").Inc()
+		slog.ErrorContext(ctx, "❌ Antrean webhook penuh, event dibuang", "event_id", event.ID, "type", event.Type)
+	}
+}
+
+// Close berhenti menerima event lalu menunggu antrean terkirim sampai ctx habis.
+// Publish tidak boleh dipanggil lagi setelah Close.
+func (w *Webhook) Close(ctx context.Context) error {
+	w.once.Do(func() { close(w.queue) })
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook: %d event belum terkirim: %w", len(w.queue), ctx.Err())
+	}
+}
+
+func (w *Webhook) run() {
+	defer close(w.done)
+	for event := range w.queue {
+		result := "success"
+		if err := w.deliver(event); err != nil {
+			result = "failure"
+			slog.Error("❌ Gagal mengirim webhook", "event_id", event.ID, "type", event.Type, "error", err)
+		}
+		metrics.WebhookDeliveriesTotal.WithLabelValues(event.Type, result).Inc()
+	}
+}
+
+// deliver mengirim satu event, dengan retry untuk error jaringan, 429 dan 5xx
+func (w *Webhook) deliver(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.send(event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.cfg.MaxRetries {
+			return fmt.Errorf("percobaan ke-%d: %w", attempt+1, err)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *Webhook) send(event Event, body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, event.ID.String())
+	req.Header.Set(HeaderEventType, event.Type)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(w.cfg.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+// Sign menghitung signature hex untuk header X-Webhook-Signature (tanpa prefix "sha256=")
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"learn_project/config"
+)
+
+func TestWebhookSignsAndRetries(t *testing.T) {
+	retryBackoff = time.Millisecond
+
+	var mu sync.Mutex
+	var attempts int
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := strings.TrimPrefix(r.Header.Get(HeaderSignature), "sha256=")
+		if signature != Sign("rahasia", r.Header.Get(HeaderTimestamp), body) {
+			t.Errorf("signature tidak cocok")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Errorf("body bukan event: %v", err)
+		}
+		if r.Header.Get(HeaderEventID) != event.ID.String() || r.Header.Get(HeaderEventType) != event.Type {
+			t.Errorf("header event tidak sesuai body")
+		}
+		received = append(received, event)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(config.WebhookConfig{URL: server.URL, Secret: "rahasia", Timeout: time.Second, MaxRetries: 2, QueueSize: 10})
+	webhook.Publish(context.Background(), New(ProductStockLow, map[string]any{"available": 2}))
+	webhook.Publish(context.Background(), New(ProductStockRestored, map[string]any{"available": 8}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := webhook.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 || len(received) != 2 || received[0].Type != ProductStockLow || received[1].Type != ProductStockRestored {
+		t.Fatalf("attempts = %d, received = %v", attempts, received)
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	retryBackoff = time.Millisecond
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(config.WebhookConfig{URL: server.URL, Secret: "rahasia", Timeout: time.Second, MaxRetries: 3, QueueSize: 10})
+	webhook.Publish(context.Background(), New(ProductStockLow, nil))
+	if err := webhook.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+}
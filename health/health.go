@@ -0,0 +1,26 @@
+// Package health menyimpan status proses yang dipakai endpoint readiness.
+package health
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+var shuttingDown atomic.Bool
+
+// SetShuttingDown menandai proses sedang graceful shutdown,
+// sehingga /readyz langsung gagal dan load balancer berhenti mengirim traffic.
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// ShuttingDown bernilai true setelah SetShuttingDown dipanggil
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// Check adalah satu pengecekan dependency untuk readiness probe
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
@@ -0,0 +1,34 @@
+// Package httperr menjalankan ErrorHandler Fiber tepat satu kali per request untuk
+// error dari handler chain.
+//
+// Middleware yang perlu status final (access log, tracing, metric) memanggil Respond
+// dengan hasil c.Next(): middleware pertama (paling dalam) yang menerima error menulis
+// response lewat app ErrorHandler, lalu error yang sama diteruskan ke middleware di
+// luarnya. Dengan begitu setiap middleware membaca status final dari response dan
+// tetap menerima error aslinya (mis. penyebab 5xx untuk log dan span). ErrorHandler
+// aplikasi memakai Responded supaya Fiber tidak menulis ulang response untuk error
+// yang dikembalikan middleware terluar.
+package httperr
+
+import "github.com/gofiber/fiber/v2"
+
+const respondedKey = "httperr_responded"
+
+// Respond menulis response untuk err lewat app ErrorHandler (kalau belum) dan
+// mengembalikan err tanpa diubah
+func Respond(c *fiber.Ctx, err error) error {
+	if err == nil || Responded(c) {
+		return err
+	}
+	if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+		_ = c.SendStatus(fiber.StatusInternalServerError)
+	}
+	c.Locals(respondedKey, true)
+	return err
+}
+
+// Responded bernilai true kalau response untuk error request ini sudah ditulis
+func Responded(c *fiber.Ctx) bool {
+	responded, _ := c.Locals(respondedKey).(bool)
+	return responded
+}
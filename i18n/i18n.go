@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Katalog pesan per bahasa, key-nya adalah kode error/sukses (mis. BANK_NOT_FOUND)
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+var (
+	bundles     = map[string]map[string]string{}
+	languages   []string
+	defaultLang = "en"
+)
+
+func init() {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		data, err := locales.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		var bundle map[string]string
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			panic(fmt.Sprintf("i18n: bundle %s tidak valid: %v", entry.Name(), err))
+		}
+
+		lang := strings.TrimSuffix(entry.Name(), ".json")
+		bundles[lang] = bundle
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+}
+
+// Languages mengembalikan daftar bahasa yang tersedia
+func Languages() []string {
+	return append([]string(nil), languages...)
+}
+
+// SetDefaultLanguage mengatur bahasa yang dipakai kalau Accept-Language tidak cocok
+func SetDefaultLanguage(lang string) error {
+	if _, ok := bundles[lang]; !ok {
+		return fmt.Errorf("i18n: bahasa %q tidak tersedia (tersedia: %s)", lang, strings.Join(languages, ", "))
+	}
+	defaultLang = lang
+	return nil
+}
+
+// DefaultLanguage mengembalikan bahasa default
+func DefaultLanguage() string {
+	return defaultLang
+}
+
+// Language memilih bahasa dari header Accept-Language, atau bahasa default.
+// Bahasa default ditaruh paling depan supaya "Accept-Language: *" memilihnya.
+func Language(c *fiber.Ctx) string {
+	if c.Get(fiber.HeaderAcceptLanguage) == "" {
+		return defaultLang
+	}
+
+	offers := make([]string, 0, len(languages))
+	offers = append(offers, defaultLang)
+	for _, lang := range languages {
+		if lang != defaultLang {
+			offers = append(offers, lang)
+		}
+	}
+
+	if lang := c.AcceptsLanguages(offers...); lang != "" {
+		return lang
+	}
+	return defaultLang
+}
+
+// Translate mengembalikan pesan untuk key dalam bahasa lang.
+// Kalau tidak ada, coba bahasa default, lalu fallback.
+func Translate(lang, key, fallback string) string {
+	if msg, ok := bundles[lang][key]; ok {
+		return msg
+	}
+	if msg, ok := bundles[defaultLang][key]; ok {
+		return msg
+	}
+	return fallback
+}
+
+// Lookup mengembalikan pesan key dari bundle lang saja, tanpa fallback ke bahasa default
+func Lookup(lang, key string) (string, bool) {
+	msg, ok := bundles[lang][key]
+	return msg, ok && msg != ""
+}
+
+// Message menerjemahkan key sesuai bahasa request dan menandai response
+// dengan Content-Language supaya cache membedakan tiap bahasa.
+func Message(c *fiber.Ctx, key, fallback string) string {
+	lang := Language(c)
+	c.Set(fiber.HeaderContentLanguage, lang)
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return Translate(lang, key, fallback)
+}
@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestBundlesHaveSameKeys(t *testing.T) {
+	for _, want := range []string{"en", "id"} {
+		if _, ok := bundles[want]; !ok {
+			t.Fatalf("bundle %q tidak ditemukan", want)
+		}
+	}
+
+	// Gabungan semua key dari semua bundle; setiap bundle wajib punya semuanya
+	all := map[string]bool{}
+	for _, bundle := range bundles {
+		for key := range bundle {
+			all[key] = true
+		}
+	}
+
+	for _, lang := range languages {
+		var missing []string
+		for key := range all {
+			if msg, ok := bundles[lang][key]; !ok || msg == "" {
+				missing = append(missing, key)
+			}
+		}
+		sort.Strings(missing)
+		if len(missing) > 0 {
+			t.Errorf("bundle %q tidak punya key: %v", lang, missing)
+		}
+	}
+}
+
+func TestSetDefaultLanguage(t *testing.T) {
+	defer SetDefaultLanguage(defaultLang)
+
+	if err := SetDefaultLanguage("fr"); err == nil {
+		t.Fatal("expected error for unknown language")
+	}
+	if err := SetDefaultLanguage("id"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Translate("fr", "BANK_NOT_FOUND", "x"); got != bundles["id"]["BANK_NOT_FOUND"] {
+		t.Errorf("fallback ke bahasa default gagal: %q", got)
+	}
+	if got := Translate("en", "UNKNOWN_KEY", "fallback"); got != "fallback" {
+		t.Errorf("got %q, want fallback", got)
+	}
+}
@@ -0,0 +1,119 @@
+// Package imaging memproses gambar upload: validasi format dari isi file (bukan dari
+// header Content-Type client), membuang metadata (EXIF, GPS, ...) dengan decode lalu
+// encode ulang, menerapkan orientasi EXIF sebelum dibuang, dan membuat thumbnail.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	// ErrUnsupported dikembalikan kalau isi file bukan JPEG atau PNG
+	ErrUnsupported = errors.New("imaging: unsupported image format")
+	// ErrInvalid dikembalikan kalau gambar tidak bisa di-decode
+	ErrInvalid = errors.New("imaging: invalid image")
+	// ErrTooManyPixels dikembalikan kalau dimensi gambar melebihi MaxPixels
+	ErrTooManyPixels = errors.New("imaging: image has too many pixels")
+)
+
+// MaxPixels membatasi lebar x tinggi supaya file kecil dengan dimensi raksasa
+// (decompression bomb) tidak menghabiskan memori
+const MaxPixels = 40_000_000
+
+const jpegQuality = 85
+
+// Size adalah ukuran thumbnail: sisi terpanjang paling besar MaxSide pixel
+type Size struct {
+	Name    string
+	MaxSide int
+}
+
+// Original adalah nama ukuran untuk gambar asli (sudah tanpa metadata)
+const Original = "original"
+
+// Thumbnails adalah ukuran yang dibuat untuk setiap upload
+var Thumbnails = []Size{
+	{Name: "medium", MaxSide: 800},
+	{Name: "thumb", MaxSide: 200},
+}
+
+// Image adalah hasil Process
+type Image struct {
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+	// Files berisi gambar asli (Original) dan setiap thumbnail, per nama ukuran
+	Files map[string][]byte
+}
+
+// Process memvalidasi dan memproses gambar upload
+func Process(data []byte) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	var encode func(*bytes.Buffer, image.Image) error
+	var ext string
+	switch contentType {
+	case "image/jpeg":
+		ext = ".jpg"
+		encode = func(buf *bytes.Buffer, img image.Image) error {
+			return jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality})
+		}
+	case "image/png":
+		ext = ".png"
+		encode = func(buf *bytes.Buffer, img image.Image) error {
+			return png.Encode(buf, img)
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	img := toRGBA(decoded)
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+
+	result := &Image{
+		ContentType: contentType,
+		Ext:         ext,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Files:       map[string][]byte{},
+	}
+	sizes := append([]Size{{Name: Original}}, Thumbnails...)
+	for _, size := range sizes {
+		var buf bytes.Buffer
+		if err := encode(&buf, fit(img, size.MaxSide)); err != nil {
+			return nil, err
+		}
+		result.Files[size.Name] = buf.Bytes()
+	}
+	return result, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	if img, ok := src.(*image.RGBA); ok && img.Bounds().Min == (image.Point{}) {
+		return img
+	}
+	bounds := src.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Bounds(), src, bounds.Min, draw.Src)
+	return img
+}
@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// withOrientation menyisipkan segmen EXIF APP1 berisi tag Orientation setelah SOI
+func withOrientation(jpg []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")  // big endian, IFD0 di offset 8
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // satu entry
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // sisa value + offset IFD berikutnya
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, app1...)
+	return append(out, jpg[2:]...)
+}
+
+func TestProcessJPEGAppliesOrientationAndStripsExif(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1200, 600))
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	data := withOrientation(buf.Bytes(), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("orientation = %d, want 6", jpegOrientation(data))
+	}
+
+	result, err := Process(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rotate 90: 1200x600 menjadi 600x1200
+	if result.ContentType != "image/jpeg" || result.Width != 600 || result.Height != 1200 {
+		t.Fatalf("result = %s %dx%d", result.ContentType, result.Width, result.Height)
+	}
+	for size, want := range map[string]image.Point{Original: {600, 1200}, "medium": {400, 800}, "thumb": {100, 200}} {
+		file := result.Files[size]
+		if bytes.Contains(file, []byte("Exif")) {
+			t.Fatalf("%s masih berisi EXIF", size)
+		}
+		config, format, err := image.DecodeConfig(bytes.NewReader(file))
+		if err != nil || format != "jpeg" || config.Width != want.X || config.Height != want.Y {
+			t.Fatalf("%s = %s %dx%d (err %v), want %v", size, format, config.Width, config.Height, err, want)
+		}
+	}
+}
+
+func TestProcessPNGThumbnail(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Process(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumb, err := png.Decode(bytes.NewReader(result.Files["thumb"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thumb.Bounds().Dx() != 50 || thumb.Bounds().Dy() != 200 {
+		t.Fatalf("thumb = %v", thumb.Bounds())
+	}
+	if r, g, _, a := thumb.At(25, 100).RGBA(); r>>8 != 255 || g != 0 || a>>8 != 255 {
+		t.Fatalf("warna thumb = %v", thumb.At(25, 100))
+	}
+	// Lebih kecil dari ukuran medium: tidak diperbesar
+	if config, _ := png.DecodeConfig(bytes.NewReader(result.Files["medium"])); config.Width != 100 || config.Height != 400 {
+		t.Fatalf("medium = %dx%d", config.Width, config.Height)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	if _, err := Process([]byte("GIF89a bukan gambar yang didukung")); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("gif: err = %v", err)
+	}
+	if _, err := Process([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>")); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("svg: err = %v", err)
+	}
+	// File kecil dengan dimensi raksasa ditolak sebelum di-decode
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	huge := buf.Bytes()
+	binary.BigEndian.PutUint32(huge[16:], 20000) // lebar di chunk IHDR
+	binary.BigEndian.PutUint32(huge[20:], 20000) // tinggi
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	if _, err := Process(huge); !errors.Is(err, ErrTooManyPixels) {
+		t.Fatalf("png raksasa: err = %v", err)
+	}
+
+	// Header PNG valid tapi isi rusak
+	if _, err := Process(append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("png rusak: err = %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package imaging
+
+import "image"
+
+// fit mengecilkan gambar supaya sisi terpanjangnya maxSide (0 = ukuran asli).
+// Gambar yang sudah lebih kecil tidak diperbesar.
+func fit(src *image.RGBA, maxSide int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if maxSide <= 0 || (w <= maxSide && h <= maxSide) {
+		return src
+	}
+	if w >= h {
+		return resize(src, maxSide, max(1, h*maxSide/w))
+	}
+	return resize(src, max(1, w*maxSide/h), maxSide)
+}
+
+// resize memakai box filter: setiap pixel tujuan adalah rata-rata semua pixel
+// sumber yang tertutup olehnya. Cukup bagus untuk memperkecil dan tanpa dependency.
+// Warna RGBA sudah premultiplied sehingga rata-rata transparansi tetap benar.
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (x1 - x0) * (y1 - y0)
+			offset := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[offset+i] = uint8((sum[i] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
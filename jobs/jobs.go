@@ -0,0 +1,158 @@
+// Package jobs menjalankan pekerjaan panjang (mis. import katalog besar) di background
+// dan menyimpan progresnya di memori supaya bisa dipantau lewat GET /api/jobs/:id.
+//
+// Status hanya ada di proses yang menjalankannya: dengan beberapa instance, client
+// harus diarahkan ke instance yang sama (sticky session), dan job hilang saat restart.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// retention adalah lama job yang sudah selesai disimpan sebelum dibuang
+const retention = time.Hour
+
+// Job adalah snapshot status pekerjaan
+type Job struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	OwnerID   uuid.UUID `json:"-"`
+	Status    Status    `json:"status"`
+	Total     int       `json:"total"`
+	Processed int       `json:"processed"`
+	// Result diisi fungsi job, juga kalau job gagal di tengah jalan (hasil sebagian)
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// Func adalah isi job. progress dipanggil dengan jumlah item yang sudah diproses.
+type Func func(ctx context.Context, progress func(processed int)) (result any, err error)
+
+// Manager menyimpan dan menjalankan job
+type Manager struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*Job
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewManager() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{jobs: map[uuid.UUID]*Job{}, ctx: ctx, cancel: cancel}
+}
+
+// Start menjalankan fn di goroutine baru dan langsung mengembalikan snapshot job
+func (m *Manager) Start(jobType string, ownerID uuid.UUID, total int, fn Func) Job {
+	job := &Job{
+		ID:        uuid.New(),
+		Type:      jobType,
+		OwnerID:   ownerID,
+		Status:    StatusRunning,
+		Total:     total,
+		CreatedAt: time.Now(),
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[job.ID] = job
+	snapshot := *job
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		progress := func(processed int) {
+			m.mu.Lock()
+			job.Processed = processed
+			m.mu.Unlock()
+		}
+		result, err := m.run(fn, progress)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		now := time.Now()
+		job.Result, job.FinishedAt, job.Status = result, &now, StatusSucceeded
+		if err != nil {
+			job.Status, job.Error = StatusFailed, err.Error()
+			slog.Error("job gagal", "job_id", job.ID, "type", job.Type, "error", err)
+		}
+	}()
+	return snapshot
+}
+
+// run menjalankan fn; panic dicatat sebagai job gagal, bukan mematikan server
+func (m *Manager) run(fn Func, progress func(int)) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError{r}
+		}
+	}()
+	return fn(m.ctx, progress)
+}
+
+// Get mengembalikan snapshot job
+func (m *Manager) Get(id uuid.UUID) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Close menunggu job yang masih berjalan selesai; kalau ctx habis lebih dulu,
+// job dibatalkan lewat context-nya
+func (m *Manager) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		m.cancel()
+		return nil
+	case <-ctx.Done():
+		m.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// prune membuang job yang sudah selesai lebih dari retention; dipanggil dengan mu terkunci
+func (m *Manager) prune() {
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > retention {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+type panicError struct {
+	value any
+}
+
+func (e panicError) Error() string {
+	return "panic: " + slog.AnyValue(e.value).String()
+}
@@ -0,0 +1,78 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor adalah posisi keyset pagination: nilai kolom sort dan id dari baris
+// terakhir halaman sebelumnya. Halaman berikutnya berisi baris sesudah posisi ini,
+// sehingga stabil walaupun ada insert baru.
+type Cursor struct {
+	Values []any // sesuai urutan Query.Sort: float64, string atau time.Time
+	ID     uuid.UUID
+}
+
+type cursorPayload struct {
+	Sort   string            `json:"s"`
+	Values []json.RawMessage `json:"v"`
+	ID     uuid.UUID         `json:"id"`
+}
+
+// encode mengubah cursor menjadi string opaque (base64url JSON) yang terikat ke sort q
+func (c Cursor) encode(q Query) string {
+	payload := cursorPayload{Sort: q.sortKey(), ID: c.ID}
+	for _, value := range c.Values {
+		raw, _ := json.Marshal(value)
+		payload.Values = append(payload.Values, raw)
+	}
+	encoded, _ := json.Marshal(payload)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodeCursor membaca cursor dan memastikan dibuat untuk sort yang sama dengan q.
+// Waktu dikembalikan dalam zona lokal, sama seperti timestamp yang ditulis GORM,
+// supaya perbandingan di SQLite (yang menyimpan waktu sebagai teks) konsisten.
+func decodeCursor(s string, q Query) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("malformed cursor")
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID == uuid.Nil {
+		return nil, errors.New("malformed cursor")
+	}
+	if payload.Sort != q.sortKey() || len(payload.Values) != len(q.Sort) {
+		return nil, errors.New("cursor was created for a different sort")
+	}
+
+	cursor := &Cursor{ID: payload.ID}
+	for i, s := range q.Sort {
+		var value any
+		var err error
+		switch s.Kind {
+		case Number:
+			var n float64
+			err = json.Unmarshal(payload.Values[i], &n)
+			value = n
+		case Time:
+			var t time.Time
+			err = json.Unmarshal(payload.Values[i], &t)
+			value = t.Local()
+		default:
+			var text string
+			err = json.Unmarshal(payload.Values[i], &text)
+			value = text
+		}
+		if err != nil {
+			return nil, errors.New("malformed cursor")
+		}
+		cursor.Values = append(cursor.Values, value)
+	}
+	return cursor, nil
+}
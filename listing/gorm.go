@@ -0,0 +1,118 @@
+package listing
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LikeEscape dipakai sebagai klausa ESCAPE di semua pencarian LIKE
+const LikeEscape = `ESCAPE '\'`
+
+// ContainsPattern membuat pola LIKE "mengandung" yang case-insensitive (dipasangkan
+// dengan LOWER(kolom)). Wildcard % dan _ dari input di-escape supaya dicari apa adanya.
+// LOWER + LIKE dipakai karena ILIKE hanya ada di Postgres.
+func ContainsPattern(search string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(search))
+	return "%" + escaped + "%"
+}
+
+// Where menerapkan filter, pencarian dan include_deleted ke query GORM.
+// Hasilnya dipakai untuk Count dan untuk Paginate.
+func Where(db *gorm.DB, q Query) *gorm.DB {
+	if q.IncludeDeleted {
+		db = db.Unscoped()
+	}
+
+	for _, f := range q.Filters {
+		switch f.Op {
+		case OpGte:
+			db = db.Where(f.Column+" >= ?", f.Value)
+		case OpLte:
+			db = db.Where(f.Column+" <= ?", f.Value)
+		case OpLt:
+			db = db.Where(f.Column+" < ?", f.Value)
+		case OpContains:
+			db = db.Where("LOWER("+f.Column+") LIKE ? "+LikeEscape, ContainsPattern(f.Value.(string)))
+		}
+	}
+
+	if q.Search != "" {
+		conditions := make([]string, len(q.SearchColumns))
+		args := make([]any, len(q.SearchColumns))
+		for i, column := range q.SearchColumns {
+			conditions[i] = "LOWER(" + column + ") LIKE ? " + LikeEscape
+			args[i] = ContainsPattern(q.Search)
+		}
+		db = db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+	return db
+}
+
+// Paginate menerapkan urutan sort + id, lalu cursor (keyset) atau offset, dan limit.
+// Dipanggil setelah Count supaya total tidak terpengaruh pagination.
+func Paginate(db *gorm.DB, q Query) *gorm.DB {
+	for _, s := range q.Sort {
+		if s.Desc {
+			db = db.Order(s.Column + " DESC")
+		} else {
+			db = db.Order(s.Column)
+		}
+	}
+	db = db.Order("id")
+
+	if q.After != nil {
+		condition, args := keyset(q)
+		db = db.Where(condition, args...)
+	} else if q.Offset > 0 {
+		db = db.Offset(q.Offset)
+	}
+	return db.Limit(q.fetchLimit())
+}
+
+// keyset membuat kondisi "sesudah cursor" untuk urutan campuran asc/desc:
+// (a > ?) OR (a = ? AND b < ?) OR (a = ? AND b = ? AND id > ?)
+func keyset(q Query) (string, []any) {
+	var branches []string
+	var args []any
+
+	for i := 0; i <= len(q.Sort); i++ {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, q.Sort[j].Column+" = ?")
+			args = append(args, q.After.Values[j])
+		}
+
+		if i < len(q.Sort) {
+			op := " > ?"
+			if q.Sort[i].Desc {
+				op = " < ?"
+			}
+			parts = append(parts, q.Sort[i].Column+op)
+			args = append(args, q.After.Values[i])
+		} else {
+			parts = append(parts, "id > ?")
+			args = append(args, q.After.ID)
+		}
+		branches = append(branches, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+// Find menjalankan Count dan query halaman, lalu membentuk Page beserta next cursor.
+// value mengembalikan nilai kolom sort dari sebuah baris, id mengembalikan primary key-nya.
+func Find[T any](db *gorm.DB, q Query, value func(T, string) any, id func(T) uuid.UUID) (Page[T], error) {
+	query := Where(db, q)
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return Page[T]{}, err
+	}
+
+	var items []T
+	if err := Paginate(query, q).Find(&items).Error; err != nil {
+		return Page[T]{}, err
+	}
+	return newPage(q, items, count, value, id), nil
+}
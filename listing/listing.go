@@ -0,0 +1,376 @@
+// Package listing adalah query builder untuk list endpoint: mem-parse dan memvalidasi
+// sort, filter, pencarian, include_deleted dan pagination (page atau cursor) dari
+// query string, lalu menerapkannya ke query GORM atau ke slice (repository in-memory).
+//
+// Setiap endpoint cukup mendefinisikan Spec berisi field yang boleh di-sort dan di-filter;
+// nama parameter dan pesan error-nya sama untuk semua endpoint:
+//
+//	sort=price,-created_at         urutan (prefix - untuk descending)
+//	min_<field>, max_<field>       rentang angka (Range pada field Number)
+//	<field>_from, <field>_to       rentang waktu RFC3339 atau YYYY-MM-DD (Range pada field Time)
+//	<field>=teks                   mengandung teks, case-insensitive (Contains pada field Text)
+//	search=teks                    mengandung teks di salah satu kolom Spec.Search
+//	q=teks                         full-text search (Spec.TextSearch), diurutkan berdasarkan relevansi
+//	include_deleted=true           ikutkan data yang sudah di-soft delete (Spec.SoftDelete)
+//	limit, page, cursor            pagination
+package listing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+
+	// RankColumn adalah kolom relevansi hasil ?q=. Tanpa ?sort= hasil diurutkan
+	// -rank; repository yang mendukung TextSearch wajib menyediakan kolom ini.
+	RankColumn = "rank"
+)
+
+// Kind adalah tipe nilai sebuah field
+type Kind int
+
+const (
+	Text Kind = iota
+	Number
+	Time
+)
+
+// Field adalah kolom yang boleh dipakai untuk sort atau filter
+type Field struct {
+	Name     string // kunci di parameter sort, juga nama kolom database
+	Param    string // basis nama parameter filter, default Name (mis. "created" untuk created_from)
+	Kind     Kind
+	Sortable bool
+	Range    bool // min_/max_ untuk Number, _from/_to untuk Time
+	Contains bool // ?<param>= untuk Text
+}
+
+func (f Field) param() string {
+	if f.Param != "" {
+		return f.Param
+	}
+	return f.Name
+}
+
+// Spec mendefinisikan apa yang boleh dilakukan sebuah list endpoint
+type Spec struct {
+	Fields      []Field
+	DefaultSort string   // mis. "created_at"
+	Search      []string // kolom untuk ?search=
+	SoftDelete  bool     // izinkan ?include_deleted= (hak akses dicek pemanggil)
+	TextSearch  bool     // izinkan ?q=, pencocokan dan kolom RankColumn disediakan repository
+}
+
+func (s Spec) field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+func (s Spec) sortable() []string {
+	var names []string
+	for _, f := range s.Fields {
+		if f.Sortable {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// Sort adalah satu kunci pengurutan
+type Sort struct {
+	Column string
+	Kind   Kind
+	Desc   bool
+}
+
+// Op adalah operator filter
+type Op int
+
+const (
+	OpGte Op = iota
+	OpLte
+	OpLt
+	OpContains
+)
+
+// Filter adalah satu kondisi WHERE
+type Filter struct {
+	Column string
+	Op     Op
+	Value  any // float64, time.Time atau string (pola Contains sudah lowercase)
+}
+
+// Query adalah hasil Parse yang sudah tervalidasi. Urutan selalu diakhiri id
+// supaya stabil untuk keyset pagination.
+type Query struct {
+	Sort           []Sort
+	Filters        []Filter
+	Search         string
+	SearchColumns  []string
+	Text           string // ?q=, diterapkan oleh repository
+	IncludeDeleted bool
+
+	Page   int // 0 kalau memakai cursor
+	Limit  int
+	Offset int
+	After  *Cursor
+}
+
+// fetchLimit mengambil satu baris lebih dari Limit untuk tahu apakah masih ada halaman berikutnya
+func (q Query) fetchLimit() int {
+	return q.Limit + 1
+}
+
+// sortKey adalah representasi sort untuk mengikat cursor ke urutan yang sama
+func (q Query) sortKey() string {
+	parts := make([]string, len(q.Sort))
+	for i, s := range q.Sort {
+		parts[i] = s.Column
+		if s.Desc {
+			parts[i] = "-" + s.Column
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+var (
+	ErrInvalidPagination = errors.New("invalid pagination")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidSort       = errors.New("invalid sort")
+	ErrInvalidFilter     = errors.New("invalid filter")
+)
+
+// Error menjelaskan parameter mana yang tidak valid. Err adalah salah satu ErrInvalid*.
+type Error struct {
+	Err    error
+	Param  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %s: %s", e.Err, e.Param, e.Reason)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func invalid(err error, param, format string, args ...any) *Error {
+	return &Error{Err: err, Param: param, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Parse membaca parameter list dari query string (mis. fiber c.Queries()) sesuai spec
+func Parse(spec Spec, params map[string]string) (Query, error) {
+	q := Query{Page: 1, Limit: DefaultLimit}
+
+	if spec.TextSearch {
+		q.Text = strings.TrimSpace(params["q"])
+	}
+
+	if err := q.parseSort(spec, params["sort"]); err != nil {
+		return q, err
+	}
+	if err := q.parseFilters(spec, params); err != nil {
+		return q, err
+	}
+
+	if search := strings.TrimSpace(params["search"]); search != "" && len(spec.Search) > 0 {
+		q.Search, q.SearchColumns = search, spec.Search
+	}
+
+	if raw, ok := params["include_deleted"]; ok && spec.SoftDelete {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			return q, invalid(ErrInvalidFilter, "include_deleted", "must be true or false")
+		}
+		q.IncludeDeleted = include
+	}
+
+	if err := q.parsePagination(params); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+func (q *Query) parseSort(spec Spec, raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		if q.Text != "" {
+			q.Sort = []Sort{{Column: RankColumn, Kind: Number, Desc: true}}
+			return nil
+		}
+		raw = spec.DefaultSort
+	}
+
+	seen := map[string]bool{}
+	for _, key := range strings.Split(raw, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		desc := strings.HasPrefix(key, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(key, "-"), "+")
+
+		field, ok := spec.field(name)
+		if !ok || !field.Sortable {
+			return invalid(ErrInvalidSort, "sort", "cannot sort by %q (allowed: %s)", name, strings.Join(spec.sortable(), ", "))
+		}
+		if seen[name] {
+			return invalid(ErrInvalidSort, "sort", "%q is listed more than once", name)
+		}
+		seen[name] = true
+		q.Sort = append(q.Sort, Sort{Column: field.Name, Kind: field.Kind, Desc: desc})
+	}
+	return nil
+}
+
+func (q *Query) parseFilters(spec Spec, params map[string]string) error {
+	for _, field := range spec.Fields {
+		param := field.param()
+
+		switch {
+		case field.Range && field.Kind == Number:
+			min, err := q.numberFilter(field, "min_"+param, OpGte, params)
+			if err != nil {
+				return err
+			}
+			max, err := q.numberFilter(field, "max_"+param, OpLte, params)
+			if err != nil {
+				return err
+			}
+			if min != nil && max != nil && *min > *max {
+				return invalid(ErrInvalidFilter, "min_"+param, "must not be greater than max_%s", param)
+			}
+
+		case field.Range && field.Kind == Time:
+			from, err := q.timeFilter(field, param+"_from", false, params)
+			if err != nil {
+				return err
+			}
+			to, err := q.timeFilter(field, param+"_to", true, params)
+			if err != nil {
+				return err
+			}
+			if from != nil && to != nil && from.After(*to) {
+				return invalid(ErrInvalidFilter, param+"_from", "must not be after %s_to", param)
+			}
+
+		case field.Contains && field.Kind == Text:
+			if value := strings.TrimSpace(params[param]); value != "" {
+				q.Filters = append(q.Filters, Filter{Column: field.Name, Op: OpContains, Value: strings.ToLower(value)})
+			}
+		}
+	}
+	return nil
+}
+
+func (q *Query) numberFilter(field Field, param string, op Op, params map[string]string) (*float64, error) {
+	raw, ok := params[param]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, invalid(ErrInvalidFilter, param, "must be a number")
+	}
+	q.Filters = append(q.Filters, Filter{Column: field.Name, Op: op, Value: value})
+	return &value, nil
+}
+
+// timeFilter menerima RFC3339 atau tanggal saja. Tanggal saja pada batas atas
+// berarti sampai akhir hari itu (< hari berikutnya).
+func (q *Query) timeFilter(field Field, param string, upper bool, params map[string]string) (*time.Time, error) {
+	raw, ok := params[param]
+	if !ok || raw == "" {
+		return nil, nil
+	}
+
+	if value, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		op := OpGte
+		if upper {
+			op = OpLte
+		}
+		q.Filters = append(q.Filters, Filter{Column: field.Name, Op: op, Value: value.Local()})
+		return &value, nil
+	}
+
+	day, err := time.ParseInLocation(time.DateOnly, raw, time.Local)
+	if err != nil {
+		return nil, invalid(ErrInvalidFilter, param, "must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	}
+	if upper {
+		q.Filters = append(q.Filters, Filter{Column: field.Name, Op: OpLt, Value: day.AddDate(0, 0, 1)})
+	} else {
+		q.Filters = append(q.Filters, Filter{Column: field.Name, Op: OpGte, Value: day})
+	}
+	return &day, nil
+}
+
+func (q *Query) parsePagination(params map[string]string) error {
+	if raw := params["limit"]; raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return invalid(ErrInvalidPagination, "limit", "must be between 1 and %d", MaxLimit)
+		}
+		q.Limit = limit
+	}
+
+	if raw := params["cursor"]; raw != "" {
+		cursor, err := decodeCursor(raw, *q)
+		if err != nil {
+			return invalid(ErrInvalidCursor, "cursor", "%v", err)
+		}
+		q.Page, q.After = 0, cursor
+		return nil
+	}
+
+	if raw := params["page"]; raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 || (page-1) > maxOffset/q.Limit {
+			return invalid(ErrInvalidPagination, "page", "must be a number of at least 1")
+		}
+		q.Page = page
+	}
+	q.Offset = (q.Page - 1) * q.Limit
+	return nil
+}
+
+// maxOffset membatasi offset mode page; halaman sedalam ini sebaiknya memakai cursor
+const maxOffset = 1 << 31
+
+// Page adalah satu halaman hasil list
+type Page[T any] struct {
+	Items      []T
+	Count      int64  // total data yang cocok dengan filter (tanpa memperhitungkan pagination)
+	NextCursor string // kosong kalau sudah halaman terakhir
+}
+
+// newPage membuang baris tambahan dari fetchLimit dan membuat cursor dari baris terakhir
+func newPage[T any](q Query, items []T, count int64, value func(T, string) any, id func(T) uuid.UUID) Page[T] {
+	page := Page[T]{Items: items, Count: count}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		last := page.Items[len(page.Items)-1]
+
+		cursor := Cursor{ID: id(last)}
+		for _, s := range q.Sort {
+			cursor.Values = append(cursor.Values, value(last, s.Column))
+		}
+		page.NextCursor = cursor.encode(q)
+	}
+	return page
+}
@@ -0,0 +1,121 @@
+package listing
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Slice menerapkan Query ke data in-memory dengan semantik yang sama dengan Where +
+// Paginate di GORM. Data soft delete harus sudah disaring pemanggil sesuai IncludeDeleted.
+func Slice[T any](items []T, q Query, value func(T, string) any, id func(T) uuid.UUID) Page[T] {
+	var matched []T
+	for _, item := range items {
+		if matches(q, item, value) {
+			matched = append(matched, item)
+		}
+	}
+
+	less := func(a, b T) bool {
+		return q.compare(rowOf(q, a, value, id), rowOf(q, b, value, id)) < 0
+	}
+	sort.Slice(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+
+	offset := min(q.Offset, len(matched))
+	if q.After != nil {
+		after := row{values: q.After.Values, id: q.After.ID}
+		offset = sort.Search(len(matched), func(i int) bool {
+			return q.compare(rowOf(q, matched[i], value, id), after) > 0
+		})
+	}
+
+	window := matched[offset:]
+	if len(window) > q.fetchLimit() {
+		window = window[:q.fetchLimit()]
+	}
+	return newPage(q, window, int64(len(matched)), value, id)
+}
+
+// matches mengevaluasi Filters dan Search untuk satu baris
+func matches[T any](q Query, item T, value func(T, string) any) bool {
+	for _, f := range q.Filters {
+		v := value(item, f.Column)
+		switch f.Op {
+		case OpGte:
+			if compareValues(v, f.Value) < 0 {
+				return false
+			}
+		case OpLte:
+			if compareValues(v, f.Value) > 0 {
+				return false
+			}
+		case OpLt:
+			if compareValues(v, f.Value) >= 0 {
+				return false
+			}
+		case OpContains:
+			if !strings.Contains(strings.ToLower(v.(string)), f.Value.(string)) {
+				return false
+			}
+		}
+	}
+
+	if q.Search == "" {
+		return true
+	}
+	search := strings.ToLower(q.Search)
+	for _, column := range q.SearchColumns {
+		if text, ok := value(item, column).(string); ok && strings.Contains(strings.ToLower(text), search) {
+			return true
+		}
+	}
+	return false
+}
+
+type row struct {
+	values []any
+	id     uuid.UUID
+}
+
+func rowOf[T any](q Query, item T, value func(T, string) any, id func(T) uuid.UUID) row {
+	r := row{id: id(item)}
+	for _, s := range q.Sort {
+		r.values = append(r.values, value(item, s.Column))
+	}
+	return r
+}
+
+// compare mengurutkan dua baris sesuai Query.Sort lalu id, seperti ORDER BY di GORM
+func (q Query) compare(a, b row) int {
+	for i, s := range q.Sort {
+		c := compareValues(a.values[i], b.values[i])
+		if s.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.id.String(), b.id.String())
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	return 0
+}
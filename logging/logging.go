@@ -0,0 +1,58 @@
+// Package logging menyiapkan logger JSON (log/slog) dan meneruskannya lewat context,
+// sehingga log controller dan SQL membawa request_id yang sama.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+
+	"learn_project/config"
+)
+
+type ctxKey struct{}
+
+// Key yang nilainya tidak boleh pernah muncul di log
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie"}
+
+const redacted = "[REDACTED]"
+
+// New membuat logger JSON ke stdout dengan level dari konfigurasi
+func New(cfg config.LogConfig) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	}))
+}
+
+// redact mengganti nilai attribute yang key-nya sensitif
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+	return attr
+}
+
+// WithLogger menyimpan logger di context
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext mengambil logger dari context, atau logger default kalau tidak ada
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
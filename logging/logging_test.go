@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redact}))
+
+	logger.Info("login",
+		"email", "budi@example.com",
+		"password", "rahasia123",
+		"Authorization", "Bearer abc.def.ghi",
+		"refresh_token", "xyz",
+		slog.Group("request", "headers", slog.GroupValue(slog.String("cookie", "session=1"), slog.String("accept", "application/json"))),
+	)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"password", "Authorization", "refresh_token"} {
+		if record[key] != redacted {
+			t.Errorf("%s = %v, want %s", key, record[key], redacted)
+		}
+	}
+	if record["email"] != "budi@example.com" || record["msg"] != "login" {
+		t.Errorf("record = %v", record)
+	}
+	// Key di dalam group juga disensor
+	headers := record["request"].(map[string]any)["headers"].(map[string]any)
+	if headers["cookie"] != redacted || headers["accept"] != "application/json" {
+		t.Errorf("headers = %v", headers)
+	}
+	for _, secret := range []string{"rahasia123", "abc.def.ghi", "xyz", "session=1"} {
+		if bytes.Contains(buf.Bytes(), []byte(secret)) {
+			t.Errorf("log berisi %q: %s", secret, buf.String())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package metrics mendefinisikan semua metric Prometheus aplikasi.
+// Metric diekspos lewat GET /metrics di admin port (lihat config.AdminConfig),
+// bukan di port publik.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"learn_project/httperr"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "learn_project"
+
+// Registry khusus aplikasi (bukan registry global), berisi juga metric Go runtime dan proses
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Jumlah HTTP request per route pattern dan status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Durasi HTTP request per route pattern dan status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Durasi query GORM per operasi dan tabel.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table", "status"})
+
+	RegistrationsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Jumlah user yang berhasil registrasi.",
+	})
+
+	LoginsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Jumlah percobaan login per hasil (success/failure).",
+	}, []string{"result"})
+
+	MoneyAddedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "money_added_total",
+		Help:      "Total nominal yang ditambahkan ke rekening per mata uang.",
+	}, []string{"currency"})
+
+	// TransfersTotal belum dinaikkan di mana pun: API belum punya endpoint transfer
+	// antar rekening (bank hanya mendukung tambah saldo). Handler transfer nanti
+	// menaikkannya dengan hasil success/failure.
+	TransfersTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Jumlah transfer antar rekening per hasil (success/failure).",
+	}, []string{"result"})
+
+	WebhookDeliveriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "webhook_deliveries_total",
+		Help:      "Jumlah event webhook per tipe dan hasil (success/failure/dropped).",
+	}, []string{"event", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestsTotal,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		RegistrationsTotal,
+		LoginsTotal,
+		MoneyAddedTotal,
+		TransfersTotal,
+		WebhookDeliveriesTotal,
+	)
+}
+
+// Middleware mencatat jumlah dan durasi request per route pattern (bukan URL asli,
+// supaya cardinality label tetap kecil)
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		chainErr := httperr.Respond(c, c.Next())
+
+		route := c.Route().Path
+		if c.Response().StatusCode() == fiber.StatusNotFound && route == "/" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Response().StatusCode())
+
+		HTTPRequestsTotal.WithLabelValues(c.Method(), route, status).Inc()
+		HTTPRequestDuration.WithLabelValues(c.Method(), route, status).Observe(time.Since(start).Seconds())
+		return chainErr
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"learn_project/httperr"
+	"learn_project/logging"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID adalah header untuk meneruskan request ID antar service
+const HeaderRequestID = "X-Request-ID"
+
+// Request ID dari client hanya dipakai kalau formatnya aman untuk dicatat
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestLogger memberi setiap request sebuah X-Request-ID (atau memakai yang dikirim client),
+// menyimpan logger ber-request_id di context, dan mencatat satu access log JSON per request.
+func RequestLogger(base *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		requestID := c.Get(HeaderRequestID)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(HeaderRequestID, requestID)
+		c.Locals("request_id", requestID)
+
+		logger := base.With(slog.String("request_id", requestID))
+		c.SetUserContext(logging.WithLogger(c.UserContext(), logger))
+
+		chainErr := httperr.Respond(c, c.Next())
+
+		status := c.Response().StatusCode()
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", c.Route().Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes_in", len(c.Request().Body())),
+			slog.Int("bytes_out", len(c.Response().Body())),
+			slog.String("ip", c.IP()),
+		}
+		if userID, ok := c.Locals("user_id").(string); ok && userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+		}
+		if chainErr != nil {
+			attrs = append(attrs, slog.String("error", chainErr.Error()))
+			var appErr *utils.AppError
+			if errors.As(chainErr, &appErr) {
+				attrs = append(attrs, slog.String("code", string(appErr.Code)))
+			}
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(c.UserContext(), level, "request", attrs...)
+
+		return chainErr
+	}
+}
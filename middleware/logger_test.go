@@ -0,0 +1,142 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"learn_project/metrics"
+	"learn_project/middleware"
+	"learn_project/tracing"
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newApp memasang middleware dengan urutan yang sama seperti server.go
+func newApp(logs *bytes.Buffer) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+	app.Use(middleware.RequestLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+	app.Use(tracing.Middleware())
+	app.Use(metrics.Middleware())
+	return app
+}
+
+// accessLog mengembalikan satu-satunya access log yang tercatat
+func accessLog(t *testing.T, logs *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("want 1 log line, got %d: %s", len(lines), logs)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	return record
+}
+
+// Error dari handler harus sampai ke semua middleware: response ditulis sekali,
+// access log mencatat error dan kodenya, dan span ditandai gagal
+func TestErrorChain(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var logs bytes.Buffer
+	app := newApp(&logs)
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return utils.ErrInternal.Wrap(errors.New("db down"))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/boom", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != string(utils.CodeInternal) {
+		t.Fatalf("code = %q", body.Code)
+	}
+
+	record := accessLog(t, &logs)
+	if record["level"] != "ERROR" || record["status"] != float64(500) {
+		t.Fatalf("log = %v", record)
+	}
+	if record["code"] != string(utils.CodeInternal) || !strings.Contains(record["error"].(string), "db down") {
+		t.Fatalf("log = %v", record)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("want 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /boom" || span.Status().Code != codes.Error {
+		t.Fatalf("span %q status = %v", span.Name(), span.Status())
+	}
+	if len(span.Events()) != 1 || span.Events()[0].Name != "exception" {
+		t.Fatalf("events = %v", span.Events())
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	for _, tc := range []struct {
+		name, header string
+		keep         bool
+	}{
+		{"valid", "req-123.abc_DEF", true},
+		{"missing", "", false},
+		{"invalid characters", "abc def\n", false},
+		{"too long", strings.Repeat("a", 129), false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			app := newApp(&logs)
+			app.Get("/ping", func(c *fiber.Ctx) error {
+				return c.SendString(c.Locals("request_id").(string))
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/ping", nil)
+			if tc.header != "" {
+				req.Header.Set(middleware.HeaderRequestID, tc.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := resp.Header.Get(middleware.HeaderRequestID)
+			if tc.keep && got != tc.header {
+				t.Fatalf("request ID = %q, want %q", got, tc.header)
+			}
+			if !tc.keep {
+				if _, err := uuid.Parse(got); err != nil {
+					t.Fatalf("request ID = %q, want a new UUID", got)
+				}
+			}
+			if record := accessLog(t, &logs); record["request_id"] != got {
+				t.Fatalf("log request_id = %v, header %q", record["request_id"], got)
+			}
+		})
+	}
+}
@@ -7,14 +7,14 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func Protected() fiber.Handler {
+func Protected(jwt *utils.JWTManager) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get the JWT token from the Authorization header
 		tokenString := c.Get("Authorization")
 
 		// If the Authorization header is missing or empty, return Unauthorized
 		if tokenString == "" {
-			return utils.ResponseError(c, fiber.StatusUnauthorized, "Unauthorized", nil)
+			return utils.ErrUnauthorized
 		}
 
 		// Remove "Bearer " prefix from the token string (if present)
@@ -23,13 +23,14 @@ func Protected() fiber.Handler {
 		}
 
 		// Validate the token
-		claims, err := utils.ValidateToken(tokenString)
+		claims, err := jwt.ValidateToken(tokenString)
 		if err != nil {
-			return utils.ResponseError(c, fiber.StatusUnauthorized, "Invalid token", nil)
+			return utils.ErrInvalidToken
 		}
 
-		// Add the user email to the context for use in controllers
+		// Add the user email (and ID, for access logs) to the context for use in controllers
 		c.Locals("email", claims.Email)
+		c.Locals("user_id", claims.Subject)
 
 		// Continue to the next handler
 		return c.Next()
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"learn_project/config"
+	"learn_project/database"
+	"learn_project/migrations"
+)
+
+const migrateUsage = `Penggunaan: learn_project migrate <perintah>
+
+Perintah:
+  up             jalankan semua migrasi yang belum dijalankan
+  down [n]       rollback n migrasi terakhir (default 1)
+  status         tampilkan status semua migrasi
+  create <nama>  buat file migrasi baru untuk semua driver di -dir/<driver> (default ./migrations)
+`
+
+// runMigrate menjalankan subcommand `migrate`
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := fs.String("dir", "migrations", "folder induk file migrasi per driver (untuk perintah create)")
+	fs.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	command, rest := fs.Arg(0), fs.Args()[1:]
+
+	// create tidak butuh koneksi database
+	if command == "create" {
+		if len(rest) != 1 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		dirs := make([]string, len(migrations.Drivers))
+		for i, driver := range migrations.Drivers {
+			dirs[i] = filepath.Join(*dir, driver)
+		}
+		created, err := database.CreateMigration(rest[0], dirs...)
+		for _, file := range created {
+			fmt.Println("✅ Dibuat:", file)
+		}
+		if err != nil {
+			log.Fatal("❌ Gagal membuat migrasi:", err)
+		}
+		return
+	}
+
+	// Argumen dicek sebelum koneksi ke database
+	steps := 1
+	switch command {
+	case "up", "status":
+	case "down":
+		if len(rest) > 0 {
+			n, err := strconv.Atoi(rest[0])
+			if err != nil || n < 1 {
+				log.Fatalf("❌ jumlah langkah %q tidak valid", rest[0])
+			}
+			steps = n
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := migrate(command, steps); err != nil {
+		log.Fatal("❌ ", err)
+	}
+}
+
+// migrate menjalankan perintah up, down atau status. Error dikembalikan (bukan
+// log.Fatal) supaya koneksi dan advisory lock dilepas lewat defer sebelum keluar.
+func migrate(command string, steps int) error {
+	// Hanya konfigurasi database yang dibutuhkan, jadi migrate bisa dijalankan di CI
+	// atau init container tanpa secret aplikasi
+	cfg, err := config.LoadDatabase()
+	if err != nil {
+		return err
+	}
+	database.Connect(cfg.Database, cfg.Log)
+	defer database.Close()
+
+	migrationFS, err := migrations.FS(cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(database.DB, migrationFS)
+	if err != nil {
+		return fmt.Errorf("gagal membaca file migrasi: %w", err)
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("✅ %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Tidak ada migrasi baru")
+		}
+
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("↩️  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSI\tNAMA\tSTATUS")
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, state)
+		}
+		return w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+// Package migrations berisi file SQL migrasi yang di-embed ke binary,
+// satu folder per driver database (postgres, sqlite) dengan versi yang sama.
+//
+// Format nama file: <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql,
+// buat file baru dengan `go run . migrate create <nama>` (dibuat untuk semua driver).
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// Drivers adalah driver database yang punya folder migrasi
+var Drivers = []string{"postgres", "sqlite"}
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// FS mengembalikan file migrasi untuk driver database tertentu
+func FS(driver string) (fs.FS, error) {
+	if slices.Contains(Drivers, driver) {
+		return fs.Sub(files, driver)
+	}
+	return nil, fmt.Errorf("tidak ada migrasi untuk driver %q", driver)
+}
@@ -0,0 +1,39 @@
+-- Skema awal, sama dengan hasil AutoMigrate sebelumnya.
+-- Memakai IF NOT EXISTS supaya database lama yang dibuat oleh AutoMigrate bisa diadopsi.
+
+CREATE TABLE IF NOT EXISTS users (
+    id          UUID PRIMARY KEY,
+    name        TEXT,
+    email       TEXT NOT NULL,
+    password    TEXT,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS products (
+    id           UUID PRIMARY KEY,
+    name         TEXT NOT NULL,
+    description  TEXT,
+    price        DECIMAL NOT NULL,
+    created_at   TIMESTAMPTZ,
+    updated_at   TIMESTAMPTZ,
+    deleted_at   TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+
+CREATE TABLE IF NOT EXISTS banks (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL,
+    bank_name   TEXT NOT NULL,
+    account_no  TEXT NOT NULL,
+    nominal     DECIMAL DEFAULT 0,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_banks_account_no ON banks (account_no);
+CREATE INDEX IF NOT EXISTS idx_banks_user_id ON banks (user_id);
+CREATE INDEX IF NOT EXISTS idx_banks_deleted_at ON banks (deleted_at);
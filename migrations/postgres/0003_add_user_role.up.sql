@@ -0,0 +1,3 @@
+-- Role user (user atau admin). Admin diberikan manual, mis.:
+--   UPDATE users SET role = 'admin' WHERE email = 'admin@example.com';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
@@ -0,0 +1,15 @@
+-- Full-text search produk (?q= di GET /api/products).
+-- search_vector dihitung otomatis dari name (bobot A) dan description (bobot B).
+-- Konfigurasi 'simple' (tanpa stemming) karena isi produk campuran Indonesia/Inggris.
+-- pg_trgm dipakai untuk toleransi typo lewat similarity nama; butuh hak CREATE
+-- di database (atau extension sudah dipasang oleh superuser).
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
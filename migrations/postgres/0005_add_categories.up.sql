@@ -0,0 +1,20 @@
+-- Kategori produk bertingkat (parent_id) dan relasi many-to-many produk <-> kategori.
+-- Kategori yang masih punya anak tidak bisa dihapus (RESTRICT); relasi produk ikut terhapus.
+CREATE TABLE IF NOT EXISTS categories (
+    id          UUID PRIMARY KEY,
+    parent_id   UUID REFERENCES categories (id) ON DELETE RESTRICT,
+    name        TEXT NOT NULL,
+    slug        VARCHAR(100) NOT NULL,
+    sort_order  INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug);
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id   UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    category_id  UUID NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, category_id)
+);
+CREATE INDEX IF NOT EXISTS idx_product_categories_category_id ON product_categories (category_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS inventory_movements;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_stock;
+ALTER TABLE products DROP COLUMN IF EXISTS low_stock_threshold;
+ALTER TABLE products DROP COLUMN IF EXISTS reserved;
+ALTER TABLE products DROP COLUMN IF EXISTS stock;
@@ -0,0 +1,21 @@
+-- Stok produk dan riwayat perubahannya.
+-- Stok diubah dengan UPDATE bersyarat (lihat repository ApplyMovement); CHECK di bawah
+-- adalah pengaman terakhir supaya stock/reserved tidak pernah negatif.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reserved BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS low_stock_threshold BIGINT;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS chk_products_stock;
+ALTER TABLE products ADD CONSTRAINT chk_products_stock CHECK (reserved >= 0 AND stock >= reserved);
+
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id              UUID PRIMARY KEY,
+    product_id      UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    user_id         UUID REFERENCES users (id) ON DELETE SET NULL,
+    type            VARCHAR(20) NOT NULL,
+    quantity        BIGINT NOT NULL,
+    reason          TEXT NOT NULL DEFAULT '',
+    stock_after     BIGINT NOT NULL,
+    reserved_after  BIGINT NOT NULL,
+    created_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_id ON inventory_movements (product_id, created_at);
@@ -0,0 +1,15 @@
+-- Option produk (size, color, ...) dan varian per SKU dengan harga dan stok sendiri
+ALTER TABLE products ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id          UUID PRIMARY KEY,
+    product_id  UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku         VARCHAR(64) NOT NULL,
+    price       DECIMAL,
+    stock       BIGINT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    attributes  JSONB NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_sku ON product_variants (sku);
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id);
@@ -0,0 +1,12 @@
+-- Metadata gambar produk; file asli dan thumbnail disimpan di BlobStore
+CREATE TABLE IF NOT EXISTS product_images (
+    id            UUID PRIMARY KEY,
+    product_id    UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    position      INTEGER NOT NULL DEFAULT 0,
+    content_type  VARCHAR(50) NOT NULL,
+    width         INTEGER NOT NULL,
+    height        INTEGER NOT NULL,
+    size          BIGINT NOT NULL,
+    created_at    TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_product_images_product_position ON product_images (product_id, position);
@@ -0,0 +1,3 @@
+-- SKU produk (opsional) sebagai kunci upsert import katalog
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products (sku);
@@ -0,0 +1,14 @@
+-- Riwayat perubahan produk, satu baris per versi (append-only).
+-- Produk yang dibuat sebelum migrasi ini riwayatnya mulai dari perubahan berikutnya.
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id             UUID PRIMARY KEY,
+    product_id     UUID NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    version        INTEGER NOT NULL,
+    user_id        UUID REFERENCES users (id) ON DELETE SET NULL,
+    action         VARCHAR(20) NOT NULL,
+    reverted_from  INTEGER,
+    changes        JSONB NOT NULL DEFAULT '{}',
+    snapshot       JSONB NOT NULL,
+    created_at     TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_revisions_product_version ON product_revisions (product_id, version);
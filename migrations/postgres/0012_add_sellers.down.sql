@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_products_seller_id;
+ALTER TABLE products DROP COLUMN IF EXISTS seller_id;
+DROP TABLE IF EXISTS sellers;
@@ -0,0 +1,39 @@
+-- Skema awal untuk SQLite (development lokal dan test), sama dengan versi Postgres.
+-- UUID disimpan sebagai TEXT, waktu sebagai DATETIME supaya driver mengembalikan time.Time.
+
+CREATE TABLE IF NOT EXISTS users (
+    id          TEXT PRIMARY KEY,
+    name        TEXT,
+    email       TEXT NOT NULL,
+    password    TEXT,
+    created_at  DATETIME,
+    updated_at  DATETIME,
+    deleted_at  DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS products (
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL,
+    description  TEXT,
+    price        REAL NOT NULL,
+    created_at   DATETIME,
+    updated_at   DATETIME,
+    deleted_at   DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+
+CREATE TABLE IF NOT EXISTS banks (
+    id          TEXT PRIMARY KEY,
+    user_id     TEXT NOT NULL,
+    bank_name   TEXT NOT NULL,
+    account_no  TEXT NOT NULL,
+    nominal     REAL DEFAULT 0,
+    created_at  DATETIME,
+    updated_at  DATETIME,
+    deleted_at  DATETIME
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_banks_account_no ON banks (account_no);
+CREATE INDEX IF NOT EXISTS idx_banks_user_id ON banks (user_id);
+CREATE INDEX IF NOT EXISTS idx_banks_deleted_at ON banks (deleted_at);
@@ -0,0 +1,2 @@
+-- Mata uang rekening (ISO 4217), rekening lama dianggap IDR
+ALTER TABLE banks ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'IDR';
@@ -0,0 +1,3 @@
+-- SQLite tidak punya tsvector/pg_trgm: ?q= memakai LIKE di name dan description
+-- (lihat repository/search.go). Migrasi ini hanya menjaga versi tetap sama dengan postgres.
+SELECT 1;
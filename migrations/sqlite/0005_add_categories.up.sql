@@ -0,0 +1,20 @@
+-- Kategori produk bertingkat (parent_id) dan relasi many-to-many produk <-> kategori.
+-- Kategori yang masih punya anak tidak bisa dihapus (RESTRICT); relasi produk ikut terhapus.
+CREATE TABLE categories (
+    id          TEXT PRIMARY KEY,
+    parent_id   TEXT REFERENCES categories (id) ON DELETE RESTRICT,
+    name        TEXT NOT NULL,
+    slug        TEXT NOT NULL,
+    sort_order  INTEGER NOT NULL DEFAULT 0,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+CREATE UNIQUE INDEX idx_categories_slug ON categories (slug);
+CREATE INDEX idx_categories_parent_id ON categories (parent_id);
+
+CREATE TABLE product_categories (
+    product_id   TEXT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    category_id  TEXT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, category_id)
+);
+CREATE INDEX idx_product_categories_category_id ON product_categories (category_id);
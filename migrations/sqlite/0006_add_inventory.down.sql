@@ -0,0 +1,4 @@
+DROP TABLE inventory_movements;
+ALTER TABLE products DROP COLUMN low_stock_threshold;
+ALTER TABLE products DROP COLUMN reserved;
+ALTER TABLE products DROP COLUMN stock;
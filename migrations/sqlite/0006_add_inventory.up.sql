@@ -0,0 +1,19 @@
+-- Stok produk dan riwayat perubahannya.
+-- Stok diubah dengan UPDATE bersyarat (lihat repository ApplyMovement); CHECK di bawah
+-- adalah pengaman terakhir supaya stock/reserved tidak pernah negatif.
+ALTER TABLE products ADD COLUMN stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0);
+ALTER TABLE products ADD COLUMN reserved INTEGER NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+ALTER TABLE products ADD COLUMN low_stock_threshold INTEGER;
+
+CREATE TABLE inventory_movements (
+    id              TEXT PRIMARY KEY,
+    product_id      TEXT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    user_id         TEXT REFERENCES users (id) ON DELETE SET NULL,
+    type            TEXT NOT NULL,
+    quantity        INTEGER NOT NULL,
+    reason          TEXT NOT NULL DEFAULT '',
+    stock_after     INTEGER NOT NULL,
+    reserved_after  INTEGER NOT NULL,
+    created_at      DATETIME
+);
+CREATE INDEX idx_inventory_movements_product_id ON inventory_movements (product_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE product_variants;
+ALTER TABLE products DROP COLUMN options;
@@ -0,0 +1,16 @@
+-- Option produk (size, color, ...) dan varian per SKU dengan harga dan stok sendiri.
+-- Kolom JSON disimpan sebagai TEXT.
+ALTER TABLE products ADD COLUMN options TEXT NOT NULL DEFAULT '[]';
+
+CREATE TABLE product_variants (
+    id          TEXT PRIMARY KEY,
+    product_id  TEXT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku         TEXT NOT NULL,
+    price       REAL,
+    stock       INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    attributes  TEXT NOT NULL DEFAULT '{}',
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+CREATE UNIQUE INDEX idx_product_variants_sku ON product_variants (sku);
+CREATE INDEX idx_product_variants_product_id ON product_variants (product_id);
@@ -0,0 +1,12 @@
+-- Metadata gambar produk; file asli dan thumbnail disimpan di BlobStore
+CREATE TABLE product_images (
+    id            TEXT PRIMARY KEY,
+    product_id    TEXT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    position      INTEGER NOT NULL DEFAULT 0,
+    content_type  TEXT NOT NULL,
+    width         INTEGER NOT NULL,
+    height        INTEGER NOT NULL,
+    size          INTEGER NOT NULL,
+    created_at    DATETIME
+);
+CREATE INDEX idx_product_images_product_position ON product_images (product_id, position);
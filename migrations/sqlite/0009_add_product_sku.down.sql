@@ -0,0 +1,2 @@
+DROP INDEX idx_products_sku;
+ALTER TABLE products DROP COLUMN sku;
@@ -0,0 +1,3 @@
+-- SKU produk (opsional) sebagai kunci upsert import katalog
+ALTER TABLE products ADD COLUMN sku TEXT;
+CREATE UNIQUE INDEX idx_products_sku ON products (sku);
@@ -0,0 +1,14 @@
+-- Riwayat perubahan produk, satu baris per versi (append-only).
+-- Kolom JSON disimpan sebagai TEXT.
+CREATE TABLE product_revisions (
+    id             TEXT PRIMARY KEY,
+    product_id     TEXT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    version        INTEGER NOT NULL,
+    user_id        TEXT REFERENCES users (id) ON DELETE SET NULL,
+    action         TEXT NOT NULL,
+    reverted_from  INTEGER,
+    changes        TEXT NOT NULL DEFAULT '{}',
+    snapshot       TEXT NOT NULL,
+    created_at     DATETIME
+);
+CREATE UNIQUE INDEX idx_product_revisions_product_version ON product_revisions (product_id, version);
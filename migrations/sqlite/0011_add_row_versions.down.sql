@@ -0,0 +1,2 @@
+ALTER TABLE banks DROP COLUMN version;
+ALTER TABLE products DROP COLUMN version;
@@ -0,0 +1,4 @@
+-- Nomor versi baris untuk optimistic concurrency (ETag / If-Match).
+-- Data lama mulai dari versi 1.
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE banks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
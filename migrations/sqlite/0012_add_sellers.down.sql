@@ -0,0 +1,3 @@
+DROP INDEX idx_products_seller_id;
+ALTER TABLE products DROP COLUMN seller_id;
+DROP TABLE sellers;
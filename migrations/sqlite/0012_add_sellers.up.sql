@@ -0,0 +1,21 @@
+-- Multi-merchant: profil toko per user dan pemilik (seller) setiap produk.
+-- sellers.id sama dengan users.id, jadi products.seller_id merujuk ke user pemilik
+-- sekaligus profil tokonya (kalau sudah dibuat).
+CREATE TABLE sellers (
+    id          TEXT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    store_name  TEXT NOT NULL,
+    slug        TEXT NOT NULL,
+    created_at  DATETIME,
+    updated_at  DATETIME
+);
+CREATE UNIQUE INDEX idx_sellers_slug ON sellers (slug);
+
+ALTER TABLE products ADD COLUMN seller_id TEXT REFERENCES users (id) ON DELETE SET NULL;
+CREATE INDEX idx_products_seller_id ON products (seller_id);
+
+-- Produk lama dimiliki pembuatnya menurut riwayat produk (kalau tercatat),
+-- sisanya tanpa pemilik dan hanya bisa diubah admin
+UPDATE products SET seller_id = (
+    SELECT user_id FROM product_revisions
+    WHERE product_revisions.product_id = products.id AND action = 'created'
+) WHERE seller_id IS NULL;
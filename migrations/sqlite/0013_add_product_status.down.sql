@@ -0,0 +1,3 @@
+DROP INDEX idx_products_status;
+ALTER TABLE products DROP COLUMN publish_at;
+ALTER TABLE products DROP COLUMN status;
@@ -0,0 +1,8 @@
+-- Status publikasi dan jadwal tayang produk untuk katalog publik (/catalog).
+-- Produk baru mulai sebagai draft; produk lama yang masih aktif sudah tampil ke semua
+-- user, jadi langsung published. Produk di trash tetap draft supaya tidak muncul
+-- di katalog publik begitu di-restore.
+ALTER TABLE products ADD COLUMN status TEXT NOT NULL DEFAULT 'draft';
+ALTER TABLE products ADD COLUMN publish_at DATETIME;
+UPDATE products SET status = 'published' WHERE deleted_at IS NULL;
+CREATE INDEX idx_products_status ON products (status, publish_at);
@@ -13,6 +13,8 @@ type Bank struct {
 	BankName  string         `gorm:"not null" json:"bank_name"`
 	AccountNo string         `gorm:"unique;not null" json:"account_no"`
 	Nominal   float64        `gorm:"default:0" json:"nominal"`
+	Currency  string         `gorm:"size:3;not null;default:IDR" json:"currency"` // ISO 4217, mis. IDR, USD
+	Version   int64          `gorm:"not null;default:1" json:"version"`           // naik setiap perubahan, dipakai sebagai ETag
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
@@ -21,5 +23,6 @@ type Bank struct {
 // Hook before creating a bank account (generate UUID)
 func (bank *Bank) BeforeCreate(tx *gorm.DB) (err error) {
 	bank.ID = uuid.New()
+	bank.Version = 1
 	return nil
 }
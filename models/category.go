@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Category adalah kategori produk yang bisa bersarang (parent/child).
+// Slug unik dan dipakai di URL, mis. ?category=minuman-kopi.
+type Category struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parent_id"`
+	Name      string     `gorm:"not null" json:"name"`
+	Slug      string     `gorm:"size:100;uniqueIndex;not null" json:"slug"`
+	SortOrder int        `gorm:"not null;default:0" json:"sort_order"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	// Children hanya diisi oleh endpoint tree
+	Children []*Category `gorm:"-" json:"children,omitempty"`
+}
+
+func (category *Category) BeforeCreate(tx *gorm.DB) (err error) {
+	if category.ID == uuid.Nil {
+		category.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductImage adalah gambar produk. File-nya (asli dan thumbnail) ada di BlobStore
+// dengan key dari Key; tabel ini hanya menyimpan metadata dan urutan.
+type ProductImage struct {
+	ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID   uuid.UUID `gorm:"type:uuid;not null;index:idx_product_images_product_position,priority:1" json:"product_id"`
+	Position    int       `gorm:"not null;default:0;index:idx_product_images_product_position,priority:2" json:"position"`
+	ContentType string    `gorm:"size:50;not null" json:"content_type"`
+	Width       int       `gorm:"not null" json:"width"`
+	Height      int       `gorm:"not null" json:"height"`
+	Size        int64     `gorm:"not null" json:"size"` // byte, gambar asli setelah metadata dibuang
+	CreatedAt   time.Time `json:"created_at"`
+
+	// URLs per ukuran (original, medium, thumb), diisi oleh controller
+	URLs map[string]string `gorm:"-" json:"urls"`
+}
+
+// Key adalah key BlobStore untuk ukuran size, mis. "products/<product>/<image>/thumb.jpg"
+func (image *ProductImage) Key(size string) string {
+	ext := ".jpg"
+	if image.ContentType == "image/png" {
+		ext = ".png"
+	}
+	return "products/" + image.ProductID.String() + "/" + image.ID.String() + "/" + size + ext
+}
+
+func (image *ProductImage) BeforeCreate(tx *gorm.DB) (err error) {
+	if image.ID == uuid.Nil {
+		image.ID = uuid.New()
+	}
+	return nil
+}
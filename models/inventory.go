@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MovementType adalah jenis perubahan stok
+type MovementType string
+
+const (
+	MovementReceive MovementType = "receive" // barang masuk: stock += quantity
+	MovementAdjust  MovementType = "adjust"  // koreksi stock opname: stock += quantity (boleh negatif), wajib ada reason
+	MovementReserve MovementType = "reserve" // dipesan: reserved += quantity
+	MovementRelease MovementType = "release" // pesanan batal: reserved -= quantity
+	MovementSell    MovementType = "sell"    // pesanan selesai: stock dan reserved -= quantity
+)
+
+// MovementTypes adalah semua jenis perubahan stok yang valid
+var MovementTypes = []MovementType{MovementReceive, MovementAdjust, MovementReserve, MovementRelease, MovementSell}
+
+// Deltas mengembalikan perubahan stock dan reserved untuk quantity tertentu
+func (t MovementType) Deltas(quantity int64) (stock, reserved int64) {
+	switch t {
+	case MovementReceive, MovementAdjust:
+		return quantity, 0
+	case MovementReserve:
+		return 0, quantity
+	case MovementRelease:
+		return 0, -quantity
+	case MovementSell:
+		return -quantity, -quantity
+	}
+	return 0, 0
+}
+
+// InventoryMovement adalah riwayat perubahan stok sebuah produk (append-only)
+type InventoryMovement struct {
+	ID            uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID     uuid.UUID    `gorm:"type:uuid;not null;index" json:"product_id"`
+	UserID        *uuid.UUID   `gorm:"type:uuid" json:"user_id"` // user yang melakukan perubahan
+	Type          MovementType `gorm:"size:20;not null" json:"type"`
+	Quantity      int64        `gorm:"not null" json:"quantity"`
+	Reason        string       `gorm:"not null;default:''" json:"reason"`
+	StockAfter    int64        `gorm:"not null" json:"stock_after"`
+	ReservedAfter int64        `gorm:"not null" json:"reserved_after"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+func (movement *InventoryMovement) BeforeCreate(tx *gorm.DB) (err error) {
+	if movement.ID == uuid.Nil {
+		movement.ID = uuid.New()
+	}
+	return nil
+}
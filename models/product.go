@@ -8,17 +8,78 @@ import (
 	"gorm.io/gorm"
 )
 
+// ProductStatus adalah status publikasi produk di katalog publik (/catalog)
+type ProductStatus string
+
+const (
+    ProductDraft     ProductStatus = "draft"     // belum tampil, default produk baru
+    ProductPublished ProductStatus = "published" // tampil mulai PublishAt (atau langsung kalau nil)
+    ProductArchived  ProductStatus = "archived"  // tidak dijual lagi, tidak tampil
+)
+
+// Valid bernilai true untuk status yang dikenal
+func (status ProductStatus) Valid() bool {
+    return status == ProductDraft || status == ProductPublished || status == ProductArchived
+}
+
 type Product struct {
     ID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
     Name        string    `json:"name" gorm:"not null"`
     Description string    `json:"description"`
     Price       float64   `json:"price" gorm:"not null"`
+    // SellerID adalah user pemilik produk (lihat Seller); nil untuk produk lama tanpa pemilik
+    SellerID *uuid.UUID `json:"seller_id" gorm:"type:uuid;index"`
+    // Status dan PublishAt (jadwal tayang) menentukan apakah produk tampil di katalog publik, lihat IsPublic
+    Status    ProductStatus `json:"status" gorm:"size:20;not null;default:'draft';index:idx_products_status,priority:1"`
+    PublishAt *time.Time    `json:"publish_at" gorm:"index:idx_products_status,priority:2"`
+    // SKU opsional, unik; dipakai import katalog untuk upsert
+    SKU *string `json:"sku" gorm:"column:sku;size:64;uniqueIndex"`
+    // Stok hanya berubah lewat InventoryMovement (lihat ProductRepository.ApplyMovement)
+    Stock             int64  `json:"stock" gorm:"not null;default:0"`
+    Reserved          int64  `json:"reserved" gorm:"not null;default:0"`
+    LowStockThreshold *int64 `json:"low_stock_threshold"` // nil = tidak ada notifikasi stok menipis
+    // Options adalah dimensi varian (size, color, ...), lihat ProductVariant
+    Options ProductOptions `json:"options" gorm:"not null;default:'[]'"`
+    // Version naik setiap kali produk (termasuk stok, varian dan gambarnya) berubah, dipakai sebagai ETag
+    Version int64 `json:"version" gorm:"not null;default:1"`
 		CreatedAt time.Time      `json:"created_at"` // Otomatis diisi saat pertama kali dibuat
 		UpdatedAt time.Time      `json:"updated_at"` // Diupdate otomatis oleh GORM
 		DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"` // Soft delete
+
+    Categories []Category `gorm:"many2many:product_categories" json:"categories,omitempty"`
+
+    // Hanya terisi pada hasil pencarian ?q= (read-only, bukan kolom tabel)
+    Rank      float64 `gorm:"->;-:migration" json:"rank,omitempty"`
+    Highlight string  `gorm:"->;-:migration" json:"highlight,omitempty"` // cuplikan dengan <mark>, teks sudah di-escape HTML
+}
+
+// Available adalah stok yang masih bisa di-reserve
+func (product *Product) Available() int64 {
+    return product.Stock - product.Reserved
+}
+
+// EditableBy bernilai true kalau user boleh mengubah produk: seller pemiliknya atau admin.
+// Produk tanpa seller hanya bisa diubah admin.
+func (product *Product) EditableBy(user *User) bool {
+    return user.Role == RoleAdmin || product.SellerID != nil && *product.SellerID == user.ID
+}
+
+// IsPublic bernilai true kalau produk tampil di katalog publik pada waktu now:
+// status published dan jadwal tayangnya (kalau ada) sudah lewat
+func (product *Product) IsPublic(now time.Time) bool {
+    return product.Status == ProductPublished && (product.PublishAt == nil || !product.PublishAt.After(now))
+}
+
+// IsLowStock bernilai true kalau stok tersedia sudah mencapai low_stock_threshold
+func (product *Product) IsLowStock() bool {
+    return product.LowStockThreshold != nil && product.Available() <= *product.LowStockThreshold
 }
 
 func (product *Product) BeforeCreate(tx *gorm.DB) (err error) {
     product.ID = uuid.New() // Generate a new UUID for the product
+    product.Version = 1
+    if product.Status == "" {
+        product.Status = ProductDraft
+    }
     return
 }
\ No newline at end of file
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// RevisionAction adalah jenis perubahan yang dicatat di riwayat produk
+type RevisionAction string
+
+const (
+	RevisionCreated  RevisionAction = "created"
+	RevisionUpdated  RevisionAction = "updated"
+	RevisionReverted RevisionAction = "reverted" // isi produk dikembalikan ke versi RevertedFrom
+	RevisionDeleted  RevisionAction = "deleted"
+	RevisionRestored RevisionAction = "restored" // dikeluarkan dari trash
+)
+
+// ProductSnapshot adalah isi produk yang diversikan. Stok tidak termasuk karena
+// punya riwayat sendiri (InventoryMovement) dan tidak boleh ikut di-revert.
+type ProductSnapshot struct {
+	Name              string         `json:"name"`
+	Description       string         `json:"description"`
+	Price             float64        `json:"price"`
+	SKU               *string        `json:"sku"`
+	CategoryIDs       []uuid.UUID    `json:"category_ids"`
+	Options           ProductOptions `json:"options"`
+	LowStockThreshold *int64         `json:"low_stock_threshold"`
+	// Status publikasi ikut dicatat di riwayat, tapi tidak ikut di-revert
+	Status    ProductStatus `json:"status"`
+	PublishAt *time.Time    `json:"publish_at"`
+}
+
+// FieldChange adalah nilai satu field sebelum dan sesudah perubahan
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// ProductChanges adalah field snapshot yang berubah, dengan nama field JSON sebagai key
+type ProductChanges map[string]FieldChange
+
+// ProductRevision adalah satu versi produk (append-only): siapa mengubah apa dan kapan.
+// Version berurutan per produk mulai dari 1.
+type ProductRevision struct {
+	ID           uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID    uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_product_revisions_product_version,priority:1" json:"product_id"`
+	Version      int             `gorm:"not null;uniqueIndex:idx_product_revisions_product_version,priority:2" json:"version"`
+	UserID       *uuid.UUID      `gorm:"type:uuid" json:"user_id"` // nil kalau user sudah dihapus
+	Action       RevisionAction  `gorm:"size:20;not null" json:"action"`
+	RevertedFrom *int            `json:"reverted_from,omitempty"`
+	Changes      ProductChanges  `gorm:"not null" json:"changes"`
+	Snapshot     ProductSnapshot `gorm:"not null" json:"snapshot"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+func (revision *ProductRevision) BeforeCreate(tx *gorm.DB) (err error) {
+	if revision.ID == uuid.Nil {
+		revision.ID = uuid.New()
+	}
+	return nil
+}
+
+// Snapshot mengambil isi produk yang diversikan; kategori cukup ID-nya, diurutkan
+// supaya urutan tampil kategori tidak dianggap perubahan
+func (product *Product) Snapshot() ProductSnapshot {
+	ids := make([]uuid.UUID, len(product.Categories))
+	for i, category := range product.Categories {
+		ids[i] = category.ID
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	options := product.Options
+	if options == nil {
+		options = ProductOptions{}
+	}
+	// Dalam UTC supaya zona waktu input dan database tidak dianggap perubahan
+	var publishAt *time.Time
+	if product.PublishAt != nil {
+		utc := product.PublishAt.UTC()
+		publishAt = &utc
+	}
+	return ProductSnapshot{
+		Name:              product.Name,
+		Description:       product.Description,
+		Price:             product.Price,
+		SKU:               product.SKU,
+		CategoryIDs:       ids,
+		Options:           options,
+		LowStockThreshold: product.LowStockThreshold,
+		Status:            product.Status,
+		PublishAt:         publishAt,
+	}
+}
+
+// Diff mengembalikan field yang berbeda antara snapshot ini dan next
+func (snapshot ProductSnapshot) Diff(next ProductSnapshot) ProductChanges {
+	changes := ProductChanges{}
+	compare := func(field string, from, to any) {
+		if !reflect.DeepEqual(from, to) {
+			changes[field] = FieldChange{From: from, To: to}
+		}
+	}
+	compare("name", snapshot.Name, next.Name)
+	compare("description", snapshot.Description, next.Description)
+	compare("price", snapshot.Price, next.Price)
+	compare("sku", snapshot.SKU, next.SKU)
+	compare("category_ids", snapshot.CategoryIDs, next.CategoryIDs)
+	compare("options", snapshot.Options, next.Options)
+	compare("low_stock_threshold", snapshot.LowStockThreshold, next.LowStockThreshold)
+	compare("status", snapshot.Status, next.Status)
+	compare("publish_at", snapshot.PublishAt, next.PublishAt)
+	return changes
+}
+
+func (snapshot ProductSnapshot) Value() (driver.Value, error) {
+	return jsonValue(snapshot)
+}
+
+func (snapshot *ProductSnapshot) Scan(value any) error {
+	return scanJSON(value, snapshot)
+}
+
+func (ProductSnapshot) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (changes ProductChanges) Value() (driver.Value, error) {
+	if changes == nil {
+		changes = ProductChanges{}
+	}
+	return jsonValue(changes)
+}
+
+func (changes *ProductChanges) Scan(value any) error {
+	return scanJSON(value, changes)
+}
+
+func (ProductChanges) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
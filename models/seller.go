@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Seller adalah profil toko seorang user (multi-merchant). ID sama dengan ID user
+// pemiliknya, jadi Product.SellerID merujuk ke user sekaligus profil tokonya.
+// Slug unik dan dipakai di URL publik, mis. /sellers/toko-budi/products.
+type Seller struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	StoreName string    `gorm:"size:100;not null" json:"store_name"`
+	Slug      string    `gorm:"size:100;uniqueIndex;not null" json:"slug"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,18 +1,32 @@
 package models
 
 import (
+	"context"
 	"time"
 
+	"learn_project/tracing"
+
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// PasswordCost adalah cost bcrypt untuk hash password.
+// Test boleh menurunkannya ke bcrypt.MinCost supaya tidak lambat.
+var PasswordCost = 14
+
+// Role user: admin boleh melihat data yang sudah dihapus (include_deleted)
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID        uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
 	Name      string         `json:"name"`
 	Email     string         `json:"email" gorm:"unique;not null"`
 	Password  string         `json:"password"`
+	Role      string         `gorm:"size:20;not null;default:user" json:"role"`
 	CreatedAt time.Time      `json:"created_at"` // Otomatis diisi saat pertama kali dibuat
 	UpdatedAt time.Time      `json:"updated_at"` // Diupdate otomatis oleh GORM
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"` // Soft delete
@@ -25,8 +39,11 @@ func (user *User) BeforeCreate(tx *gorm.DB) (err error) {
 }
 
 // Hash password sebelum disimpan
-func (user *User) HashPassword(password string) error {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+func (user *User) HashPassword(ctx context.Context, password string) error {
+	_, span := tracing.Start(ctx, "bcrypt.hash")
+	defer span.End()
+
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), PasswordCost)
 	if err != nil {
 		return err
 	}
@@ -35,6 +52,9 @@ func (user *User) HashPassword(password string) error {
 }
 
 // Validasi password
-func (user *User) CheckPassword(providedPassword string) error {
+func (user *User) CheckPassword(ctx context.Context, providedPassword string) error {
+	_, span := tracing.Start(ctx, "bcrypt.compare")
+	defer span.End()
+
 	return bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(providedPassword))
 }
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ProductOption adalah dimensi varian sebuah produk, mis. {"name": "size", "values": ["S", "M", "L"]}
+type ProductOption struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// ProductOptions disimpan sebagai JSON di kolom products.options
+type ProductOptions []ProductOption
+
+// VariantAttributes adalah nilai option sebuah varian, mis. {"size": "M", "color": "merah"}.
+// Disimpan sebagai JSON di kolom product_variants.attributes.
+type VariantAttributes map[string]string
+
+// ProductVariant adalah satu SKU dari sebuah produk dengan harga dan stok sendiri
+type ProductVariant struct {
+	ID         uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	ProductID  uuid.UUID         `gorm:"type:uuid;not null;index" json:"product_id"`
+	SKU        string            `gorm:"column:sku;size:64;uniqueIndex;not null" json:"sku"`
+	Price      *float64          `json:"price"` // nil = memakai harga produk
+	Stock      int64             `gorm:"not null;default:0" json:"stock"`
+	Attributes VariantAttributes `gorm:"not null" json:"attributes"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+
+	// EffectivePrice adalah Price atau harga produk, diisi oleh controller
+	EffectivePrice float64 `gorm:"-" json:"effective_price"`
+}
+
+// PriceFor mengembalikan harga varian, atau harga produk kalau tidak di-override
+func (variant *ProductVariant) PriceFor(product *Product) float64 {
+	if variant.Price != nil {
+		return *variant.Price
+	}
+	return product.Price
+}
+
+func (variant *ProductVariant) BeforeCreate(tx *gorm.DB) (err error) {
+	if variant.ID == uuid.Nil {
+		variant.ID = uuid.New()
+	}
+	return nil
+}
+
+func (options ProductOptions) Value() (driver.Value, error) {
+	if options == nil {
+		options = ProductOptions{}
+	}
+	return jsonValue(options)
+}
+
+func (options *ProductOptions) Scan(value any) error {
+	return scanJSON(value, options)
+}
+
+func (ProductOptions) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func (attributes VariantAttributes) Value() (driver.Value, error) {
+	if attributes == nil {
+		attributes = VariantAttributes{}
+	}
+	return jsonValue(attributes)
+}
+
+func (attributes *VariantAttributes) Scan(value any) error {
+	return scanJSON(value, attributes)
+}
+
+func (VariantAttributes) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return jsonDataType(db)
+}
+
+func jsonValue(value any) (driver.Value, error) {
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+func scanJSON(value, dest any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	case nil:
+		return nil
+	}
+	return fmt.Errorf("tidak bisa membaca %T sebagai JSON", value)
+}
+
+// jsonDataType adalah tipe kolom JSON untuk AutoMigrate
+func jsonDataType(db *gorm.DB) string {
+	if db.Dialector.Name() == "postgres" {
+		return "JSONB"
+	}
+	return "TEXT"
+}
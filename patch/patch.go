@@ -0,0 +1,105 @@
+// Package patch menerapkan JSON Merge Patch (RFC 7396) dan JSON Patch (RFC 6902)
+// ke dokumen JSON. Endpoint PATCH mengubah resource menjadi dokumen JSON berisi
+// field yang boleh ditulis, menerapkan patch, lalu membaca dan memvalidasi hasilnya
+// sama seperti body PUT.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+)
+
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+
+	// Accepted adalah nilai header Accept-Patch untuk endpoint PATCH
+	Accepted = MergePatchType + ", " + JSONPatchType
+)
+
+var (
+	// ErrUnsupportedType dikembalikan untuk Content-Type selain merge patch dan JSON Patch
+	ErrUnsupportedType = errors.New("patch: unsupported content type")
+	// ErrInvalid: patch tidak valid atau path-nya tidak ada di dokumen
+	ErrInvalid = errors.New("patch: invalid patch")
+	// ErrTestFailed: operasi test JSON Patch tidak cocok dengan dokumen
+	ErrTestFailed = errors.New("patch: test failed")
+)
+
+// Error menjelaskan kenapa patch gagal diterapkan; Unwrap mengembalikan ErrInvalid atau ErrTestFailed
+type Error struct {
+	Op     int // index operasi JSON Patch yang gagal, -1 untuk merge patch
+	Reason string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error() + ": " + e.Reason
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Apply menerapkan patch ke doc sesuai Content-Type. application/json diperlakukan
+// sebagai merge patch karena banyak client tidak mengirim media type yang spesifik.
+func Apply(contentType string, doc, patch []byte) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case MergePatchType, "application/json":
+		return MergePatch(doc, patch)
+	case JSONPatchType:
+		return JSONPatch(doc, patch)
+	}
+	return nil, ErrUnsupportedType
+}
+
+// MergePatch menerapkan JSON Merge Patch: member bernilai null dihapus, object
+// digabung rekursif, nilai lain (termasuk array) menggantikan nilai lama.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := decode(patch)
+	if err != nil {
+		return nil, &Error{Op: -1, Reason: "patch is not valid JSON", Err: ErrInvalid}
+	}
+	return json.Marshal(merge(target, changes))
+}
+
+func merge(target, patch any) any {
+	changes, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]any)
+	if !ok {
+		object = map[string]any{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(object, key)
+		} else {
+			object[key] = merge(object[key], value)
+		}
+	}
+	return object
+}
+
+// decode membaca JSON dengan json.Number supaya angka tidak kehilangan presisi
+func decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return value, nil
+}
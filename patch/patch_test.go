@@ -0,0 +1,123 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// sameJSON membandingkan dua dokumen JSON tanpa memperhatikan urutan member
+func sameJSON(t *testing.T, got []byte, want string) {
+	t.Helper()
+
+	var a, b any
+	if err := json.Unmarshal(got, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &b); err != nil {
+		t.Fatal(err)
+	}
+	if !equal(normalize(a), normalize(b)) {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func normalize(value any) any {
+	data, _ := json.Marshal(value)
+	out, _ := decode(data)
+	return out
+}
+
+// Contoh dari RFC 7396 Appendix A
+func TestMergePatch(t *testing.T) {
+	for _, tc := range []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"price":12345678901234567890}`, `{}`, `{"price":12345678901234567890}`},
+	} {
+		got, err := MergePatch([]byte(tc.doc), []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("%s + %s: %v", tc.doc, tc.patch, err)
+		}
+		sameJSON(t, got, tc.want)
+	}
+
+	if _, err := MergePatch([]byte(`{}`), []byte(`{`)); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("err = %v, want ErrInvalid", err)
+	}
+}
+
+// Contoh dari RFC 6902 Appendix A
+func TestJSONPatch(t *testing.T) {
+	for _, tc := range []struct{ doc, patch, want string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"foo":"bar","child":{"grandchild":{}}}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"replace","path":"/~1","value":null}]`, `{"/":null,"~1":10}`},
+		{`{"a":{"b":[1]}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/b/-","value":2}]`, `{"a":{"b":[1]},"c":{"b":[1,2]}}`},
+		{`{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+	} {
+		got, err := JSONPatch([]byte(tc.doc), []byte(tc.patch))
+		if err != nil {
+			t.Fatalf("%s + %s: %v", tc.doc, tc.patch, err)
+		}
+		sameJSON(t, got, tc.want)
+	}
+}
+
+func TestJSONPatchErrors(t *testing.T) {
+	for _, tc := range []struct {
+		doc, patch string
+		op         int
+		want       error
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, 0, ErrInvalid},
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, 0, ErrTestFailed},
+		{`{"foo":"bar"}`, `[{"op":"test","path":"/foo","value":"bar"},{"op":"replace","path":"/nope","value":1}]`, 1, ErrInvalid},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/01","value":1}]`, 0, ErrInvalid},
+		{`{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/1"}]`, 0, ErrInvalid},
+		{`{"foo":{}}`, `[{"op":"move","from":"/foo","path":"/foo/bar"}]`, 0, ErrInvalid},
+		{`{"foo":1}`, `[{"op":"add","path":"/bar"}]`, 0, ErrInvalid},
+		{`{"foo":1}`, `[{"op":"add","path":"bar","value":1}]`, 0, ErrInvalid},
+		{`{"foo":1}`, `[{"op":"frobnicate","path":"/foo"}]`, 0, ErrInvalid},
+		{`{"foo":1}`, `{"op":"remove","path":"/foo"}`, -1, ErrInvalid},
+	} {
+		_, err := JSONPatch([]byte(tc.doc), []byte(tc.patch))
+		var patchErr *Error
+		if !errors.Is(err, tc.want) || !errors.As(err, &patchErr) || patchErr.Op != tc.op {
+			t.Errorf("%s + %s: err = %v, want %v at op %d", tc.doc, tc.patch, err, tc.want, tc.op)
+		}
+	}
+}
+
+func TestApplyContentType(t *testing.T) {
+	doc := []byte(`{"a":1,"b":2}`)
+	if got, err := Apply("application/merge-patch+json; charset=utf-8", doc, []byte(`{"a":null}`)); err != nil {
+		t.Fatal(err)
+	} else {
+		sameJSON(t, got, `{"b":2}`)
+	}
+	if got, err := Apply("application/json-patch+json", doc, []byte(`[{"op":"remove","path":"/b"}]`)); err != nil {
+		t.Fatal(err)
+	} else {
+		sameJSON(t, got, `{"a":1}`)
+	}
+	if _, err := Apply("text/plain", doc, []byte(`{}`)); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("err = %v, want ErrUnsupportedType", err)
+	}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// translateError memetakan error GORM ke error repository.
+// Butuh gorm.Config{TranslateError: true} supaya unique violation menjadi gorm.ErrDuplicatedKey.
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"learn_project/listing"
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormBankRepository struct {
+	db *gorm.DB
+}
+
+// NewGormBankRepository membuat BankRepository berbasis GORM
+func NewGormBankRepository(db *gorm.DB) BankRepository {
+	return &gormBankRepository{db: db}
+}
+
+func (r *gormBankRepository) Create(ctx context.Context, bank *models.Bank) error {
+	return translateError(r.db.WithContext(ctx).Create(bank).Error)
+}
+
+func (r *gormBankRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Bank, error) {
+	var bank models.Bank
+	if err := r.db.WithContext(ctx).First(&bank, "id = ?", id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &bank, nil
+}
+
+func (r *gormBankRepository) FindByAccountNo(ctx context.Context, accountNo string) (*models.Bank, error) {
+	var bank models.Bank
+	if err := r.db.WithContext(ctx).Where("account_no = ?", accountNo).First(&bank).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &bank, nil
+}
+
+func (r *gormBankRepository) ListByUser(ctx context.Context, userID uuid.UUID, q listing.Query) (listing.Page[models.Bank], error) {
+	query := r.db.WithContext(ctx).Model(&models.Bank{}).Where("user_id = ?", userID)
+	return listing.Find(query, q, bankValue, bankID)
+}
+
+func (r *gormBankRepository) Update(ctx context.Context, bank *models.Bank) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// UPDATE bersyarat version: kalau bank sudah diubah request lain, tidak ada baris yang kena
+		query := tx.Model(&models.Bank{}).Where("id = ?", bank.ID)
+		if bank.Version != 0 {
+			query = query.Where("version = ?", bank.Version)
+		}
+		result := query.Updates(map[string]any{
+			"bank_name":  bank.BankName,
+			"account_no": bank.AccountNo,
+			"version":    gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return bankVersionConflict(tx, bank.ID)
+		}
+		return tx.Select("version", "updated_at").First(bank, "id = ?", bank.ID).Error
+	}))
+}
+
+func (r *gormBankRepository) AddMoney(ctx context.Context, id uuid.UUID, amount float64) (*models.Bank, error) {
+	var bank models.Bank
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// nominal = nominal + ? dihitung database, jadi setoran paralel tidak saling menimpa;
+		// kondisi nominal + ? >= 0 menjaga saldo tidak negatif di dalam UPDATE yang sama
+		result := tx.Model(&models.Bank{}).Where("id = ? AND nominal + ? >= 0", id, amount).Updates(map[string]any{
+			"nominal": gorm.Expr("nominal + ?", amount),
+			"version": gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			// Bank ada berarti saldonya yang tidak cukup
+			if err := tx.Select("id").First(&models.Bank{}, "id = ?", id).Error; err != nil {
+				return err
+			}
+			return ErrInsufficientFunds
+		}
+		return tx.First(&bank, "id = ?", id).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &bank, nil
+}
+
+func (r *gormBankRepository) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	db := r.db.WithContext(ctx)
+	query := db.Where("id = ?", id)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(&models.Bank{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Sama seperti sebelumnya, delete bank yang tidak ada bukan error
+		if err := bankVersionConflict(db, id); !errors.Is(err, ErrNotFound) {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+// bankVersionConflict membedakan penyebab UPDATE/DELETE bersyarat yang tidak mengenai
+// baris mana pun: bank sudah tidak ada (ErrNotFound) atau version-nya sudah berubah
+func bankVersionConflict(db *gorm.DB, id uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Bank{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return ErrVersionConflict
+}
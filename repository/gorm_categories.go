@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// categorySubtreeSQL memilih id kategori ? beserta semua turunannya.
+// UNION (bukan UNION ALL) menghentikan rekursi kalau data ternyata membentuk siklus.
+const categorySubtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE id = ?
+	UNION
+	SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+) SELECT id FROM subtree`
+
+// categoryOrder adalah urutan tampil kategori
+const categoryOrder = "sort_order, name"
+
+type gormCategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCategoryRepository membuat CategoryRepository berbasis GORM
+func NewGormCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &gormCategoryRepository{db: db}
+}
+
+func (r *gormCategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return translateError(r.db.WithContext(ctx).Create(category).Error)
+}
+
+func (r *gormCategoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(ctx).First(&category, "id = ?", id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &category, nil
+}
+
+func (r *gormCategoryRepository) FindBySlug(ctx context.Context, slug string) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(ctx).First(&category, "slug = ?", slug).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &category, nil
+}
+
+func (r *gormCategoryRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Order(categoryOrder).Find(&categories).Error
+	return categories, translateError(err)
+}
+
+func (r *gormCategoryRepository) List(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.WithContext(ctx).Order(categoryOrder).Find(&categories).Error
+	return categories, translateError(err)
+}
+
+func (r *gormCategoryRepository) Subtree(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.WithContext(ctx).Raw(categorySubtreeSQL, id).Scan(&ids).Error; err != nil {
+		return nil, translateError(err)
+	}
+	if len(ids) == 0 {
+		return nil, ErrNotFound
+	}
+	return ids, nil
+}
+
+func (r *gormCategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return translateError(r.db.WithContext(ctx).Save(category).Error)
+}
+
+func (r *gormCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var children int64
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrInUse
+		}
+
+		result := tx.Delete(&models.Category{}, "id = ?", id)
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// imageOrder adalah urutan tampil gambar; created_at memutus posisi yang sama
+// (dua upload bersamaan bisa mendapat position yang sama)
+const imageOrder = "position, created_at"
+
+type gormImageRepository struct {
+	db *gorm.DB
+}
+
+// NewGormImageRepository membuat ImageRepository berbasis GORM
+func NewGormImageRepository(db *gorm.DB) ImageRepository {
+	return &gormImageRepository{db: db}
+}
+
+func (r *gormImageRepository) Create(ctx context.Context, image *models.ProductImage, productVersion int64) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := touchProduct(tx, image.ProductID, productVersion); err != nil {
+			return err
+		}
+
+		var next int
+		err := tx.Model(&models.ProductImage{}).
+			Where("product_id = ?", image.ProductID).
+			Select("COALESCE(MAX(position), -1) + 1").
+			Scan(&next).Error
+		if err != nil {
+			return err
+		}
+		image.Position = next
+		return tx.Create(image).Error
+	}))
+}
+
+func (r *gormImageRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.ProductImage, error) {
+	var image models.ProductImage
+	if err := r.db.WithContext(ctx).First(&image, "id = ?", id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &image, nil
+}
+
+func (r *gormImageRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]models.ProductImage, error) {
+	images := []models.ProductImage{}
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order(imageOrder).Find(&images).Error
+	return images, translateError(err)
+}
+
+func (r *gormImageRepository) Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID, productVersion int64) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := touchProduct(tx, productID, productVersion); err != nil {
+			return err
+		}
+
+		for position, id := range ids {
+			err := tx.Model(&models.ProductImage{}).
+				Where("id = ? AND product_id = ?", id, productID).
+				Update("position", position).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (r *gormImageRepository) Delete(ctx context.Context, productID, id uuid.UUID, productVersion int64) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := touchProduct(tx, productID, productVersion); err != nil {
+			return err
+		}
+		result := tx.Delete(&models.ProductImage{}, "id = ? AND product_id = ?", id, productID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"context"
+
+	"learn_project/listing"
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type gormProductRepository struct {
+	db       *gorm.DB
+	fullText bool // search_vector tersedia, lihat search.go
+}
+
+// NewGormProductRepository membuat ProductRepository berbasis GORM.
+// Dukungan full-text search dicek sekali di sini, jadi repository harus dibuat
+// setelah migrasi dijalankan.
+func NewGormProductRepository(db *gorm.DB) ProductRepository {
+	return &gormProductRepository{db: db, fullText: hasFullText(db)}
+}
+
+// Categories.* di-omit supaya GORM hanya menulis baris product_categories,
+// bukan meng-upsert data kategorinya
+func (r *gormProductRepository) Create(ctx context.Context, product *models.Product, revision *models.ProductRevision) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories.*").Create(product).Error; err != nil {
+			return err
+		}
+		revision.Action = models.RevisionCreated
+		return recordRevision(tx, product, models.ProductChanges{}, revision)
+	}))
+}
+
+func (r *gormProductRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	var product models.Product
+	if err := r.db.WithContext(ctx).Scopes(preloadCategories).Where("id = ?", id).First(&product).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &product, nil
+}
+
+func (r *gormProductRepository) FindBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	var product models.Product
+	if err := r.db.WithContext(ctx).Scopes(preloadCategories).Where("sku = ?", sku).First(&product).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &product, nil
+}
+
+func (r *gormProductRepository) List(ctx context.Context, q listing.Query, filter ProductFilter) (listing.Page[models.Product], error) {
+	db := r.db.WithContext(ctx).Model(&models.Product{})
+	if q.Text != "" {
+		db = searchProducts(db, q, r.fullText)
+	}
+	if filter.Trash {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if filter.SellerID != nil {
+		db = db.Where("seller_id = ?", *filter.SellerID)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.PublicAt != nil {
+		db = db.Where("status = ? AND (publish_at IS NULL OR publish_at <= ?)", models.ProductPublished, *filter.PublicAt)
+	}
+	if filter.CategoryID != nil {
+		db = db.Where("id IN (SELECT product_id FROM product_categories WHERE category_id IN ("+categorySubtreeSQL+"))", *filter.CategoryID)
+	}
+	return listing.Find(db.Scopes(preloadCategories), q, productValue, productID)
+}
+
+// Update menyimpan kolom produk lalu mengganti isi product_categories sesuai product.Categories.
+// Stock dan reserved tidak ikut disimpan supaya tidak menimpa ApplyMovement yang berjalan paralel.
+// Version dibandingkan setelah baris dikunci, jadi dua update paralel dari versi yang
+// sama tidak bisa sama-sama berhasil.
+func (r *gormProductRepository) Update(ctx context.Context, product *models.Product, revision *models.ProductRevision) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockProduct(tx, product.ID, false)
+		if err != nil {
+			return err
+		}
+		if product.Version != 0 && product.Version != current.Version {
+			return ErrVersionConflict
+		}
+
+		changes := current.Snapshot().Diff(product.Snapshot())
+		changed := len(changes) > 0 || revision.Action == models.RevisionReverted
+		product.Version = current.Version
+		if changed {
+			product.Version++
+		}
+
+		if err := tx.Omit(clause.Associations, "stock", "reserved").Save(product).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(product).Omit("Categories.*").Association("Categories").Replace(product.Categories); err != nil {
+			return err
+		}
+
+		if !changed {
+			return nil
+		}
+		if revision.Action == "" {
+			revision.Action = models.RevisionUpdated
+		}
+		return recordRevision(tx, product, changes, revision)
+	}))
+}
+
+func (r *gormProductRepository) Delete(ctx context.Context, id uuid.UUID, version int64, revision *models.ProductRevision) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		product, err := lockProduct(tx, id, false)
+		if err != nil {
+			return err
+		}
+		if version != 0 && product.Version != version {
+			return ErrVersionConflict
+		}
+		if err := tx.Delete(product).Error; err != nil {
+			return err
+		}
+		revision.Action = models.RevisionDeleted
+		return recordRevision(tx, product, models.ProductChanges{}, revision)
+	}))
+}
+
+func (r *gormProductRepository) Restore(ctx context.Context, id uuid.UUID, revision *models.ProductRevision) (*models.Product, error) {
+	var product *models.Product
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if product, err = lockProduct(tx, id, true); err != nil {
+			return err
+		}
+		product.Version++
+		if err := tx.Unscoped().Model(product).Updates(map[string]any{"deleted_at": nil, "version": product.Version}).Error; err != nil {
+			return err
+		}
+		product.DeletedAt = gorm.DeletedAt{}
+		revision.Action = models.RevisionRestored
+		return recordRevision(tx, product, models.ProductChanges{}, revision)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return product, nil
+}
+
+func (r *gormProductRepository) Revisions(ctx context.Context, productID uuid.UUID, q listing.Query) (listing.Page[models.ProductRevision], error) {
+	db := r.db.WithContext(ctx).Model(&models.ProductRevision{}).Where("product_id = ?", productID)
+	return listing.Find(db, q, revisionValue, revisionID)
+}
+
+func (r *gormProductRepository) FindRevision(ctx context.Context, productID uuid.UUID, version int) (*models.ProductRevision, error) {
+	var revision models.ProductRevision
+	if err := r.db.WithContext(ctx).Where("product_id = ? AND version = ?", productID, version).First(&revision).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &revision, nil
+}
+
+// lockProduct mengunci baris produk (SELECT ... FOR UPDATE, diabaikan SQLite) lalu
+// membacanya beserta kategori. deleted memilih produk aktif atau yang ada di trash.
+// Kategori dibaca di query terpisah supaya baris kategori tidak ikut terkunci.
+func lockProduct(tx *gorm.DB, id uuid.UUID, deleted bool) (*models.Product, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", id)
+	if deleted {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if err := query.First(&models.Product{}).Error; err != nil {
+		return nil, err
+	}
+
+	var product models.Product
+	if err := tx.Unscoped().Scopes(preloadCategories).First(&product, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// recordRevision mencatat versi berikutnya produk. Dipanggil setelah baris produk
+// dikunci, jadi penulisan paralel ke produk yang sama mendapat nomor versi berurutan.
+func recordRevision(tx *gorm.DB, product *models.Product, changes models.ProductChanges, revision *models.ProductRevision) error {
+	var latest int
+	err := tx.Model(&models.ProductRevision{}).Where("product_id = ?", product.ID).Select("COALESCE(MAX(version), 0)").Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	revision.ProductID, revision.Version = product.ID, latest+1
+	revision.Changes, revision.Snapshot = changes, product.Snapshot()
+	return tx.Create(revision).Error
+}
+
+func (r *gormProductRepository) Touch(ctx context.Context, id uuid.UUID, version int64) error {
+	return translateError(touchProduct(r.db.WithContext(ctx), id, version))
+}
+
+// touchProduct menaikkan version produk dengan UPDATE bersyarat version (kalau bukan 0).
+// Dipanggil di awal transaksi perubahan varian dan gambar, sehingga baris produk
+// terkunci sampai transaksi selesai dan perubahan paralel dari version yang sama
+// tidak bisa sama-sama berhasil.
+func touchProduct(tx *gorm.DB, id uuid.UUID, version int64) error {
+	query := tx.Model(&models.Product{}).Where("id = ?", id)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Update("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Produk sudah tidak ada (atau di trash) atau version-nya sudah berubah
+		var count int64
+		if err := tx.Model(&models.Product{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrNotFound
+		}
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// eachBatchSize adalah jumlah produk per query Each
+var eachBatchSize = 500
+
+func (r *gormProductRepository) Each(ctx context.Context, fn func(models.Product) error) error {
+	var last *models.Product
+	for {
+		query := r.db.WithContext(ctx).Scopes(preloadCategories).Order("created_at, id").Limit(eachBatchSize)
+		if last != nil {
+			// Keyset, bukan OFFSET: tetap cepat di batch terakhir dan tidak melompati
+			// baris kalau ada produk yang dihapus selama export
+			query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", last.CreatedAt, last.CreatedAt, last.ID)
+		}
+
+		var batch []models.Product
+		if err := query.Find(&batch).Error; err != nil {
+			return translateError(err)
+		}
+		for _, product := range batch {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+		if len(batch) < eachBatchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+func (r *gormProductRepository) ApplyMovement(ctx context.Context, movement *models.InventoryMovement) (*models.Product, error) {
+	stock, reserved := movement.Type.Deltas(movement.Quantity)
+
+	var product models.Product
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Satu UPDATE bersyarat, bukan SELECT lalu UPDATE: kondisi dievaluasi ulang
+		// terhadap baris terbaru sehingga reservasi paralel tidak bisa melebihi stok
+		result := tx.Model(&models.Product{}).
+			Where("id = ? AND reserved + ? >= 0 AND stock + ? >= reserved + ?", movement.ProductID, reserved, stock, reserved).
+			Updates(map[string]any{
+				"stock":    gorm.Expr("stock + ?", stock),
+				"reserved": gorm.Expr("reserved + ?", reserved),
+				"version":  gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if err := tx.Scopes(preloadCategories).First(&product, "id = ?", movement.ProductID).Error; err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+
+		movement.StockAfter, movement.ReservedAfter = product.Stock, product.Reserved
+		return tx.Create(movement).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &product, nil
+}
+
+func (r *gormProductRepository) Movements(ctx context.Context, productID uuid.UUID, q listing.Query) (listing.Page[models.InventoryMovement], error) {
+	db := r.db.WithContext(ctx).Model(&models.InventoryMovement{}).Where("product_id = ?", productID)
+	return listing.Find(db, q, movementValue, movementID)
+}
+
+func preloadCategories(db *gorm.DB) *gorm.DB {
+	return db.Preload("Categories", func(db *gorm.DB) *gorm.DB {
+		return db.Order(categoryOrder)
+	})
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormSellerRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSellerRepository membuat SellerRepository berbasis GORM
+func NewGormSellerRepository(db *gorm.DB) SellerRepository {
+	return &gormSellerRepository{db: db}
+}
+
+func (r *gormSellerRepository) Create(ctx context.Context, seller *models.Seller) error {
+	return translateError(r.db.WithContext(ctx).Create(seller).Error)
+}
+
+func (r *gormSellerRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Seller, error) {
+	var seller models.Seller
+	if err := r.db.WithContext(ctx).First(&seller, "id = ?", id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &seller, nil
+}
+
+func (r *gormSellerRepository) FindBySlug(ctx context.Context, slug string) (*models.Seller, error) {
+	var seller models.Seller
+	if err := r.db.WithContext(ctx).First(&seller, "slug = ?", slug).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &seller, nil
+}
+
+func (r *gormSellerRepository) Update(ctx context.Context, seller *models.Seller) error {
+	return translateError(r.db.WithContext(ctx).Save(seller).Error)
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type gormVariantRepository struct {
+	db *gorm.DB
+}
+
+// NewGormVariantRepository membuat VariantRepository berbasis GORM
+func NewGormVariantRepository(db *gorm.DB) VariantRepository {
+	return &gormVariantRepository{db: db}
+}
+
+func (r *gormVariantRepository) Create(ctx context.Context, variant *models.ProductVariant, productVersion int64) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := touchProduct(tx, variant.ProductID, productVersion); err != nil {
+			return err
+		}
+		return tx.Create(variant).Error
+	}))
+}
+
+func (r *gormVariantRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := r.db.WithContext(ctx).First(&variant, "id = ?", id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &variant, nil
+}
+
+func (r *gormVariantRepository) ListByProduct(ctx context.Context, productID uuid.UUID) ([]models.ProductVariant, error) {
+	variants := []models.ProductVariant{}
+	err := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at, sku").Find(&variants).Error
+	return variants, translateError(err)
+}
+
+func (r *gormVariantRepository) Update(ctx context.Context, variant *models.ProductVariant, productVersion int64) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := touchProduct(tx, variant.ProductID, productVersion); err != nil {
+			return err
+		}
+		return tx.Save(variant).Error
+	}))
+}
+
+func (r *gormVariantRepository) Delete(ctx context.Context, productID, id uuid.UUID, productVersion int64) error {
+	return translateError(r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := touchProduct(tx, productID, productVersion); err != nil {
+			return err
+		}
+		result := tx.Delete(&models.ProductVariant{}, "id = ? AND product_id = ?", id, productID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	}))
+}
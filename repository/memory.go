@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// prepareCreate mengisi ID dan timestamp seperti hook BeforeCreate + GORM
+func prepareCreate(id *uuid.UUID, createdAt, updatedAt *time.Time) {
+	if *id == uuid.Nil {
+		*id = uuid.New()
+	}
+	now := time.Now()
+	*createdAt, *updatedAt = now, now
+}
+
+func isDeleted(deletedAt gorm.DeletedAt) bool {
+	return deletedAt.Valid
+}
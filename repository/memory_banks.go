@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"learn_project/listing"
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type memoryBankRepository struct {
+	mu    sync.RWMutex
+	banks map[uuid.UUID]models.Bank
+}
+
+// NewMemoryBankRepository membuat BankRepository in-memory untuk test
+func NewMemoryBankRepository() BankRepository {
+	return &memoryBankRepository{banks: map[uuid.UUID]models.Bank{}}
+}
+
+func (r *memoryBankRepository) Create(_ context.Context, bank *models.Bank) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.accountNoTaken(bank.AccountNo, uuid.Nil) {
+		return ErrDuplicate
+	}
+	if bank.Currency == "" {
+		bank.Currency = "IDR"
+	}
+
+	prepareCreate(&bank.ID, &bank.CreatedAt, &bank.UpdatedAt)
+	bank.Version = 1
+	r.banks[bank.ID] = *bank
+	return nil
+}
+
+func (r *memoryBankRepository) FindByID(_ context.Context, id uuid.UUID) (*models.Bank, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	bank, ok := r.banks[id]
+	if !ok || isDeleted(bank.DeletedAt) {
+		return nil, ErrNotFound
+	}
+	return &bank, nil
+}
+
+func (r *memoryBankRepository) FindByAccountNo(_ context.Context, accountNo string) (*models.Bank, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, bank := range r.banks {
+		if bank.AccountNo == accountNo && !isDeleted(bank.DeletedAt) {
+			return &bank, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryBankRepository) ListByUser(_ context.Context, userID uuid.UUID, q listing.Query) (listing.Page[models.Bank], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var banks []models.Bank
+	for _, bank := range r.banks {
+		if bank.UserID == userID && (q.IncludeDeleted || !isDeleted(bank.DeletedAt)) {
+			banks = append(banks, bank)
+		}
+	}
+	return listing.Slice(banks, q, bankValue, bankID), nil
+}
+
+func (r *memoryBankRepository) Update(_ context.Context, bank *models.Bank) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.banks[bank.ID]
+	if !ok || isDeleted(existing.DeletedAt) {
+		return ErrNotFound
+	}
+	if bank.Version != 0 && bank.Version != existing.Version {
+		return ErrVersionConflict
+	}
+	if r.accountNoTaken(bank.AccountNo, bank.ID) {
+		return ErrDuplicate
+	}
+
+	existing.BankName, existing.AccountNo = bank.BankName, bank.AccountNo
+	existing.Version++
+	existing.UpdatedAt = time.Now()
+	r.banks[bank.ID] = existing
+	bank.Version, bank.UpdatedAt = existing.Version, existing.UpdatedAt
+	return nil
+}
+
+func (r *memoryBankRepository) AddMoney(_ context.Context, id uuid.UUID, amount float64) (*models.Bank, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bank, ok := r.banks[id]
+	if !ok || isDeleted(bank.DeletedAt) {
+		return nil, ErrNotFound
+	}
+	if bank.Nominal+amount < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	bank.Nominal += amount
+	bank.Version++
+	bank.UpdatedAt = time.Now()
+	r.banks[id] = bank
+	return &bank, nil
+}
+
+func (r *memoryBankRepository) Delete(_ context.Context, id uuid.UUID, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bank, ok := r.banks[id]
+	if !ok || isDeleted(bank.DeletedAt) {
+		return nil // sama seperti GORM: delete data yang tidak ada bukan error
+	}
+	if version != 0 && bank.Version != version {
+		return ErrVersionConflict
+	}
+	bank.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.banks[id] = bank
+	return nil
+}
+
+// accountNoTaken mengecek unique constraint account_no (termasuk data soft delete, seperti di database)
+func (r *memoryBankRepository) accountNoTaken(accountNo string, exceptID uuid.UUID) bool {
+	for _, bank := range r.banks {
+		if bank.AccountNo == accountNo && bank.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryCategoryRepository struct {
+	mu         sync.RWMutex
+	categories map[uuid.UUID]models.Category
+}
+
+// NewMemoryCategoryRepository membuat CategoryRepository in-memory untuk test
+func NewMemoryCategoryRepository() CategoryRepository {
+	return &memoryCategoryRepository{categories: map[uuid.UUID]models.Category{}}
+}
+
+func (r *memoryCategoryRepository) Create(_ context.Context, category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.slugTaken(category.Slug, uuid.Nil) {
+		return ErrDuplicate
+	}
+	prepareCreate(&category.ID, &category.CreatedAt, &category.UpdatedAt)
+	r.categories[category.ID] = *category
+	return nil
+}
+
+func (r *memoryCategoryRepository) FindByID(_ context.Context, id uuid.UUID) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &category, nil
+}
+
+func (r *memoryCategoryRepository) FindBySlug(_ context.Context, slug string) (*models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, category := range r.categories {
+		if category.Slug == slug {
+			return &category, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryCategoryRepository) FindByIDs(_ context.Context, ids []uuid.UUID) ([]models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := []models.Category{}
+	for _, id := range ids {
+		if category, ok := r.categories[id]; ok && !slices.ContainsFunc(categories, func(c models.Category) bool { return c.ID == id }) {
+			categories = append(categories, category)
+		}
+	}
+	sortCategories(categories)
+	return categories, nil
+}
+
+func (r *memoryCategoryRepository) List(_ context.Context) ([]models.Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	categories := []models.Category{}
+	for _, category := range r.categories {
+		categories = append(categories, category)
+	}
+	sortCategories(categories)
+	return categories, nil
+}
+
+func (r *memoryCategoryRepository) Subtree(_ context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return nil, ErrNotFound
+	}
+	ids := []uuid.UUID{id}
+	for i := 0; i < len(ids); i++ {
+		for _, category := range r.categories {
+			if category.ParentID != nil && *category.ParentID == ids[i] && !slices.Contains(ids, category.ID) {
+				ids = append(ids, category.ID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryCategoryRepository) Update(_ context.Context, category *models.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[category.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.slugTaken(category.Slug, category.ID) {
+		return ErrDuplicate
+	}
+	category.UpdatedAt = time.Now()
+	r.categories[category.ID] = *category
+	return nil
+}
+
+func (r *memoryCategoryRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.categories[id]; !ok {
+		return ErrNotFound
+	}
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == id {
+			return ErrInUse
+		}
+	}
+	delete(r.categories, id)
+	return nil
+}
+
+func (r *memoryCategoryRepository) slugTaken(slug string, except uuid.UUID) bool {
+	for _, category := range r.categories {
+		if category.Slug == slug && category.ID != except {
+			return true
+		}
+	}
+	return false
+}
+
+// sortCategories mengurutkan seperti ORDER BY sort_order, name
+func sortCategories(categories []models.Category) {
+	slices.SortFunc(categories, func(a, b models.Category) int {
+		return cmp.Or(cmp.Compare(a.SortOrder, b.SortOrder), cmp.Compare(a.Name, b.Name))
+	})
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryImageRepository struct {
+	mu     sync.RWMutex
+	images map[uuid.UUID]models.ProductImage
+
+	// products menaikkan version produk induk, lihat memoryVariantRepository
+	products ProductRepository
+}
+
+// NewMemoryImageRepository membuat ImageRepository in-memory untuk test
+func NewMemoryImageRepository(products ProductRepository) ImageRepository {
+	return &memoryImageRepository{images: map[uuid.UUID]models.ProductImage{}, products: products}
+}
+
+func (r *memoryImageRepository) Create(ctx context.Context, image *models.ProductImage, productVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.products.Touch(ctx, image.ProductID, productVersion); err != nil {
+		return err
+	}
+	image.Position = 0
+	for _, other := range r.images {
+		if other.ProductID == image.ProductID && other.Position >= image.Position {
+			image.Position = other.Position + 1
+		}
+	}
+	var updatedAt time.Time
+	prepareCreate(&image.ID, &image.CreatedAt, &updatedAt)
+	r.images[image.ID] = *image
+	return nil
+}
+
+func (r *memoryImageRepository) FindByID(_ context.Context, id uuid.UUID) (*models.ProductImage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	image, ok := r.images[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &image, nil
+}
+
+func (r *memoryImageRepository) ListByProduct(_ context.Context, productID uuid.UUID) ([]models.ProductImage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	images := []models.ProductImage{}
+	for _, image := range r.images {
+		if image.ProductID == productID {
+			images = append(images, image)
+		}
+	}
+	slices.SortFunc(images, func(a, b models.ProductImage) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), a.CreatedAt.Compare(b.CreatedAt))
+	})
+	return images, nil
+}
+
+func (r *memoryImageRepository) Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID, productVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.products.Touch(ctx, productID, productVersion); err != nil {
+		return err
+	}
+	for position, id := range ids {
+		if image, ok := r.images[id]; ok && image.ProductID == productID {
+			image.Position = position
+			r.images[id] = image
+		}
+	}
+	return nil
+}
+
+func (r *memoryImageRepository) Delete(ctx context.Context, productID, id uuid.UUID, productVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if image, ok := r.images[id]; !ok || image.ProductID != productID {
+		return ErrNotFound
+	}
+	if err := r.products.Touch(ctx, productID, productVersion); err != nil {
+		return err
+	}
+	delete(r.images, id)
+	return nil
+}
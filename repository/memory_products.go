@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"learn_project/listing"
+	"learn_project/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type memoryProductRepository struct {
+	mu        sync.RWMutex
+	products  map[uuid.UUID]models.Product
+	movements []models.InventoryMovement
+	revisions []models.ProductRevision
+
+	// categories menggantikan join ke tabel categories: relasi disimpan sebagai ID,
+	// datanya dibaca ulang setiap kali supaya rename dan delete kategori ikut terlihat
+	categories CategoryRepository
+}
+
+// NewMemoryProductRepository membuat ProductRepository in-memory untuk test
+func NewMemoryProductRepository(categories CategoryRepository) ProductRepository {
+	return &memoryProductRepository{products: map[uuid.UUID]models.Product{}, categories: categories}
+}
+
+func (r *memoryProductRepository) Create(_ context.Context, product *models.Product, revision *models.ProductRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.skuTaken(product.SKU, uuid.Nil) {
+		return ErrDuplicate
+	}
+	prepareCreate(&product.ID, &product.CreatedAt, &product.UpdatedAt)
+	product.Version = 1
+	if product.Status == "" {
+		product.Status = models.ProductDraft
+	}
+	r.products[product.ID] = *product
+
+	revision.Action = models.RevisionCreated
+	r.recordRevision(*product, models.ProductChanges{}, revision)
+	return nil
+}
+
+func (r *memoryProductRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok || isDeleted(product.DeletedAt) {
+		return nil, ErrNotFound
+	}
+	if err := r.loadCategories(ctx, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *memoryProductRepository) FindBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, product := range r.products {
+		if product.SKU != nil && *product.SKU == sku && !isDeleted(product.DeletedAt) {
+			if err := r.loadCategories(ctx, &product); err != nil {
+				return nil, err
+			}
+			return &product, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryProductRepository) List(ctx context.Context, q listing.Query, filter ProductFilter) (listing.Page[models.Product], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var subtree []uuid.UUID
+	if filter.CategoryID != nil {
+		ids, err := r.categories.Subtree(ctx, *filter.CategoryID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return listing.Page[models.Product]{}, err
+		}
+		subtree = ids
+	}
+
+	words := searchWords(q.Text)
+	var products []models.Product
+	for _, product := range r.products {
+		if !q.IncludeDeleted && isDeleted(product.DeletedAt) || filter.Trash && !isDeleted(product.DeletedAt) {
+			continue
+		}
+		if filter.SellerID != nil && (product.SellerID == nil || *product.SellerID != *filter.SellerID) {
+			continue
+		}
+		if filter.Status != "" && product.Status != filter.Status {
+			continue
+		}
+		if filter.PublicAt != nil && !product.IsPublic(*filter.PublicAt) {
+			continue
+		}
+		if len(words) > 0 {
+			rank, ok := matchProduct(product, words)
+			if !ok {
+				continue
+			}
+			product.Rank = rank
+		}
+		if err := r.loadCategories(ctx, &product); err != nil {
+			return listing.Page[models.Product]{}, err
+		}
+		if filter.CategoryID != nil && !slices.ContainsFunc(product.Categories, func(c models.Category) bool {
+			return slices.Contains(subtree, c.ID)
+		}) {
+			continue
+		}
+		products = append(products, product)
+	}
+	return listing.Slice(products, q, productValue, productID), nil
+}
+
+func (r *memoryProductRepository) ApplyMovement(ctx context.Context, movement *models.InventoryMovement) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[movement.ProductID]
+	if !ok || isDeleted(product.DeletedAt) {
+		return nil, ErrNotFound
+	}
+
+	stock, reserved := movement.Type.Deltas(movement.Quantity)
+	if product.Reserved+reserved < 0 || product.Stock+stock < product.Reserved+reserved {
+		return nil, ErrInsufficientStock
+	}
+	product.Stock += stock
+	product.Reserved += reserved
+	product.Version++
+	product.UpdatedAt = time.Now()
+	r.products[product.ID] = product
+
+	if movement.ID == uuid.Nil {
+		movement.ID = uuid.New()
+	}
+	movement.StockAfter, movement.ReservedAfter = product.Stock, product.Reserved
+	movement.CreatedAt = time.Now()
+	r.movements = append(r.movements, *movement)
+
+	if err := r.loadCategories(ctx, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *memoryProductRepository) Movements(_ context.Context, productID uuid.UUID, q listing.Query) (listing.Page[models.InventoryMovement], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var movements []models.InventoryMovement
+	for _, movement := range r.movements {
+		if movement.ProductID == productID {
+			movements = append(movements, movement)
+		}
+	}
+	return listing.Slice(movements, q, movementValue, movementID), nil
+}
+
+// loadCategories mengganti product.Categories (yang tersimpan cukup ID-nya) dengan data terbaru
+func (r *memoryProductRepository) loadCategories(ctx context.Context, product *models.Product) error {
+	if len(product.Categories) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(product.Categories))
+	for i, category := range product.Categories {
+		ids[i] = category.ID
+	}
+	categories, err := r.categories.FindByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	product.Categories = categories
+	return nil
+}
+
+func (r *memoryProductRepository) Update(_ context.Context, product *models.Product, revision *models.ProductRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.products[product.ID]
+	if !ok || isDeleted(existing.DeletedAt) {
+		return ErrNotFound
+	}
+	if product.Version != 0 && product.Version != existing.Version {
+		return ErrVersionConflict
+	}
+	if r.skuTaken(product.SKU, product.ID) {
+		return ErrDuplicate
+	}
+
+	changes := existing.Snapshot().Diff(product.Snapshot())
+	changed := len(changes) > 0 || revision.Action == models.RevisionReverted
+	product.Version = existing.Version
+	if changed {
+		product.Version++
+	}
+
+	product.Stock, product.Reserved = existing.Stock, existing.Reserved
+	product.UpdatedAt = time.Now()
+	r.products[product.ID] = *product
+
+	if !changed {
+		return nil
+	}
+	if revision.Action == "" {
+		revision.Action = models.RevisionUpdated
+	}
+	r.recordRevision(*product, changes, revision)
+	return nil
+}
+
+func (r *memoryProductRepository) Delete(_ context.Context, id uuid.UUID, version int64, revision *models.ProductRevision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || isDeleted(product.DeletedAt) {
+		return ErrNotFound
+	}
+	if version != 0 && product.Version != version {
+		return ErrVersionConflict
+	}
+	product.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.products[id] = product
+
+	revision.Action = models.RevisionDeleted
+	r.recordRevision(product, models.ProductChanges{}, revision)
+	return nil
+}
+
+func (r *memoryProductRepository) Restore(ctx context.Context, id uuid.UUID, revision *models.ProductRevision) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || !isDeleted(product.DeletedAt) {
+		return nil, ErrNotFound
+	}
+	product.DeletedAt = gorm.DeletedAt{}
+	product.Version++
+	r.products[id] = product
+
+	revision.Action = models.RevisionRestored
+	r.recordRevision(product, models.ProductChanges{}, revision)
+
+	if err := r.loadCategories(ctx, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *memoryProductRepository) Touch(_ context.Context, id uuid.UUID, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || isDeleted(product.DeletedAt) {
+		return ErrNotFound
+	}
+	if version != 0 && product.Version != version {
+		return ErrVersionConflict
+	}
+	product.Version++
+	r.products[id] = product
+	return nil
+}
+
+func (r *memoryProductRepository) Revisions(_ context.Context, productID uuid.UUID, q listing.Query) (listing.Page[models.ProductRevision], error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var revisions []models.ProductRevision
+	for _, revision := range r.revisions {
+		if revision.ProductID == productID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return listing.Slice(revisions, q, revisionValue, revisionID), nil
+}
+
+func (r *memoryProductRepository) FindRevision(_ context.Context, productID uuid.UUID, version int) (*models.ProductRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, revision := range r.revisions {
+		if revision.ProductID == productID && revision.Version == version {
+			return &revision, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// recordRevision mencatat versi berikutnya produk; dipanggil dengan mu terkunci
+func (r *memoryProductRepository) recordRevision(product models.Product, changes models.ProductChanges, revision *models.ProductRevision) {
+	latest := 0
+	for _, existing := range r.revisions {
+		if existing.ProductID == product.ID {
+			latest = max(latest, existing.Version)
+		}
+	}
+
+	if revision.ID == uuid.Nil {
+		revision.ID = uuid.New()
+	}
+	revision.ProductID, revision.Version = product.ID, latest+1
+	revision.Changes, revision.Snapshot = changes, product.Snapshot()
+	revision.CreatedAt = time.Now()
+	r.revisions = append(r.revisions, *revision)
+}
+
+func (r *memoryProductRepository) Each(ctx context.Context, fn func(models.Product) error) error {
+	// Salin dulu supaya fn boleh memanggil repository tanpa deadlock
+	r.mu.RLock()
+	var products []models.Product
+	for _, product := range r.products {
+		if !isDeleted(product.DeletedAt) {
+			products = append(products, product)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(products, func(a, b models.Product) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), strings.Compare(a.ID.String(), b.ID.String()))
+	})
+	for _, product := range products {
+		if err := r.loadCategories(ctx, &product); err != nil {
+			return err
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skuTaken mengecek unique index sku (termasuk produk yang sudah di-soft delete), kecuali untuk produk except
+func (r *memoryProductRepository) skuTaken(sku *string, except uuid.UUID) bool {
+	if sku == nil {
+		return false
+	}
+	for _, product := range r.products {
+		if product.SKU != nil && *product.SKU == *sku && product.ID != except {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+)
+
+type memorySellerRepository struct {
+	mu      sync.RWMutex
+	sellers map[uuid.UUID]models.Seller
+}
+
+// NewMemorySellerRepository membuat SellerRepository in-memory untuk test
+func NewMemorySellerRepository() SellerRepository {
+	return &memorySellerRepository{sellers: map[uuid.UUID]models.Seller{}}
+}
+
+func (r *memorySellerRepository) Create(_ context.Context, seller *models.Seller) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sellers[seller.ID]; ok || r.slugTaken(seller.Slug, seller.ID) {
+		return ErrDuplicate
+	}
+	prepareCreate(&seller.ID, &seller.CreatedAt, &seller.UpdatedAt)
+	r.sellers[seller.ID] = *seller
+	return nil
+}
+
+func (r *memorySellerRepository) FindByID(_ context.Context, id uuid.UUID) (*models.Seller, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seller, ok := r.sellers[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &seller, nil
+}
+
+func (r *memorySellerRepository) FindBySlug(_ context.Context, slug string) (*models.Seller, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, seller := range r.sellers {
+		if seller.Slug == slug {
+			return &seller, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memorySellerRepository) Update(_ context.Context, seller *models.Seller) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sellers[seller.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.slugTaken(seller.Slug, seller.ID) {
+		return ErrDuplicate
+	}
+	seller.UpdatedAt = time.Now()
+	r.sellers[seller.ID] = *seller
+	return nil
+}
+
+// slugTaken mengecek unique index slug, kecuali untuk seller except
+func (r *memorySellerRepository) slugTaken(slug string, except uuid.UUID) bool {
+	for _, seller := range r.sellers {
+		if seller.Slug == slug && seller.ID != except {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]models.User
+}
+
+// NewMemoryUserRepository membuat UserRepository in-memory untuk test
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: map[uuid.UUID]models.User{}}
+}
+
+func (r *memoryUserRepository) Create(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Unique index email di database juga berlaku untuk user yang sudah di-soft delete
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+
+	prepareCreate(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if user.Role == "" {
+		user.Role = models.RoleUser // default kolom role di database
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) FindByID(_ context.Context, id uuid.UUID) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || isDeleted(user.DeletedAt) {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(_ context.Context, email string) (*models.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email && !isDeleted(user.DeletedAt) {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryUserRepository) Update(_ context.Context, user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok || isDeleted(existing.DeletedAt) {
+		return ErrNotFound
+	}
+	for _, other := range r.users {
+		if other.ID != user.ID && other.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = *user
+	return nil
+}
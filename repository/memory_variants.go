@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"learn_project/models"
+
+	"github.com/google/uuid"
+)
+
+type memoryVariantRepository struct {
+	mu       sync.RWMutex
+	variants map[uuid.UUID]models.ProductVariant
+
+	// products menggantikan UPDATE version produk induk di transaksi yang sama.
+	// Touch dipanggil setelah semua pengecekan lain, jadi perubahan yang gagal
+	// tidak menaikkan version.
+	products ProductRepository
+}
+
+// NewMemoryVariantRepository membuat VariantRepository in-memory untuk test
+func NewMemoryVariantRepository(products ProductRepository) VariantRepository {
+	return &memoryVariantRepository{variants: map[uuid.UUID]models.ProductVariant{}, products: products}
+}
+
+func (r *memoryVariantRepository) Create(ctx context.Context, variant *models.ProductVariant, productVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.skuTaken(variant.SKU, uuid.Nil) {
+		return ErrDuplicate
+	}
+	if err := r.products.Touch(ctx, variant.ProductID, productVersion); err != nil {
+		return err
+	}
+	prepareCreate(&variant.ID, &variant.CreatedAt, &variant.UpdatedAt)
+	r.variants[variant.ID] = copyVariant(*variant)
+	return nil
+}
+
+func (r *memoryVariantRepository) FindByID(_ context.Context, id uuid.UUID) (*models.ProductVariant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	variant, ok := r.variants[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	variant = copyVariant(variant)
+	return &variant, nil
+}
+
+func (r *memoryVariantRepository) ListByProduct(_ context.Context, productID uuid.UUID) ([]models.ProductVariant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	variants := []models.ProductVariant{}
+	for _, variant := range r.variants {
+		if variant.ProductID == productID {
+			variants = append(variants, copyVariant(variant))
+		}
+	}
+	slices.SortFunc(variants, func(a, b models.ProductVariant) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.SKU, b.SKU))
+	})
+	return variants, nil
+}
+
+func (r *memoryVariantRepository) Update(ctx context.Context, variant *models.ProductVariant, productVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.variants[variant.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.skuTaken(variant.SKU, variant.ID) {
+		return ErrDuplicate
+	}
+	if err := r.products.Touch(ctx, variant.ProductID, productVersion); err != nil {
+		return err
+	}
+	variant.UpdatedAt = time.Now()
+	r.variants[variant.ID] = copyVariant(*variant)
+	return nil
+}
+
+func (r *memoryVariantRepository) Delete(ctx context.Context, productID, id uuid.UUID, productVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if variant, ok := r.variants[id]; !ok || variant.ProductID != productID {
+		return ErrNotFound
+	}
+	if err := r.products.Touch(ctx, productID, productVersion); err != nil {
+		return err
+	}
+	delete(r.variants, id)
+	return nil
+}
+
+// skuTaken mengecek unique index sku, kecuali untuk varian except
+func (r *memoryVariantRepository) skuTaken(sku string, except uuid.UUID) bool {
+	for _, variant := range r.variants {
+		if variant.SKU == sku && variant.ID != except {
+			return true
+		}
+	}
+	return false
+}
+
+// copyVariant menyalin map attributes supaya data di repository tidak ikut berubah
+func copyVariant(variant models.ProductVariant) models.ProductVariant {
+	variant.Attributes = maps.Clone(variant.Attributes)
+	return variant
+}
@@ -0,0 +1,164 @@
+// Package repository memisahkan akses data dari controller.
+// Setiap repository punya implementasi GORM (produksi) dan in-memory (test dan
+// development tanpa database).
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"learn_project/listing"
+	"learn_project/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound dikembalikan kalau data tidak ada (atau sudah di-soft delete)
+	ErrNotFound = errors.New("repository: record not found")
+	// ErrDuplicate dikembalikan kalau melanggar unique constraint (email, account_no, ...)
+	ErrDuplicate = errors.New("repository: duplicate key")
+	// ErrInUse dikembalikan kalau data masih dirujuk data lain (mis. kategori yang punya anak)
+	ErrInUse = errors.New("repository: record still referenced")
+	// ErrInsufficientStock dikembalikan kalau perubahan stok membuat stock atau reserved
+	// negatif, atau reserved melebihi stock
+	ErrInsufficientStock = errors.New("repository: insufficient stock")
+	// ErrInsufficientFunds dikembalikan kalau perubahan saldo membuat nominal negatif
+	ErrInsufficientFunds = errors.New("repository: insufficient funds")
+	// ErrVersionConflict dikembalikan kalau Version yang dikirim pemanggil sudah tidak
+	// sama dengan yang tersimpan (data diubah request lain sejak dibaca).
+	// Version 0 berarti tanpa pengecekan (mis. If-Match: *).
+	ErrVersionConflict = errors.New("repository: version conflict")
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+}
+
+// BankRepository menyimpan rekening bank. Update dan Delete memakai optimistic
+// concurrency: ErrVersionConflict kalau version tidak sama dengan yang tersimpan.
+type BankRepository interface {
+	Create(ctx context.Context, bank *models.Bank) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Bank, error)
+	FindByAccountNo(ctx context.Context, accountNo string) (*models.Bank, error)
+	// ListByUser mengembalikan satu halaman bank milik user sesuai BankListSpec
+	ListByUser(ctx context.Context, userID uuid.UUID, q listing.Query) (listing.Page[models.Bank], error)
+	// Update menyimpan bank_name dan account_no lalu menaikkan bank.Version.
+	// Nominal tidak ikut disimpan, saldo hanya berubah lewat AddMoney.
+	Update(ctx context.Context, bank *models.Bank) error
+	// AddMoney menambah saldo secara atomik (aman dipanggil paralel) dan
+	// mengembalikan bank sesudah perubahan. Saldo tidak pernah negatif:
+	// perubahan yang membuatnya negatif ditolak dengan ErrInsufficientFunds.
+	AddMoney(ctx context.Context, id uuid.UUID, amount float64) (*models.Bank, error)
+	Delete(ctx context.Context, id uuid.UUID, version int64) error
+}
+
+// ProductFilter adalah filter produk di luar listing.Query
+type ProductFilter struct {
+	// CategoryID membatasi ke produk di kategori ini atau salah satu turunannya
+	CategoryID *uuid.UUID
+	// SellerID membatasi ke produk milik seller (user) ini
+	SellerID *uuid.UUID
+	// Status membatasi ke produk dengan status publikasi ini
+	Status models.ProductStatus
+	// PublicAt membatasi ke produk yang tampil di katalog publik pada waktu ini (Product.IsPublic)
+	PublicAt *time.Time
+	// Trash hanya mengembalikan produk yang sudah di-soft delete (Query.IncludeDeleted harus true)
+	Trash bool
+}
+
+// ProductRepository menyimpan produk beserta relasi kategorinya: Create dan Update
+// menyimpan product.Categories (cukup ID), FindByID dan List mengisinya.
+//
+// Update dan Delete memakai optimistic concurrency: ErrVersionConflict kalau
+// product.Version (atau version) tidak sama dengan yang tersimpan. Setiap perubahan
+// produk, termasuk stok lewat ApplyMovement, menaikkan Version.
+//
+// Create, Update, Delete dan Restore mencatat ProductRevision di transaksi yang sama.
+// Pemanggil mengisi revision.UserID (dan Action/RevertedFrom untuk revert lewat Update),
+// repository mengisi sisanya. Update yang tidak mengubah apa pun tidak membuat versi
+// baru (revision.Version tetap 0), kecuali revert.
+type ProductRepository interface {
+	Create(ctx context.Context, product *models.Product, revision *models.ProductRevision) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Product, error)
+	FindBySKU(ctx context.Context, sku string) (*models.Product, error)
+	// List mengembalikan satu halaman produk sesuai ProductListSpec dan filter
+	List(ctx context.Context, q listing.Query, filter ProductFilter) (listing.Page[models.Product], error)
+	Update(ctx context.Context, product *models.Product, revision *models.ProductRevision) error
+	// Delete memindahkan produk ke trash (soft delete)
+	Delete(ctx context.Context, id uuid.UUID, version int64, revision *models.ProductRevision) error
+	// Restore mengeluarkan produk dari trash; ErrNotFound kalau produk tidak ada di trash
+	Restore(ctx context.Context, id uuid.UUID, revision *models.ProductRevision) (*models.Product, error)
+	// Revisions mengembalikan satu halaman riwayat produk sesuai RevisionListSpec
+	Revisions(ctx context.Context, productID uuid.UUID, q listing.Query) (listing.Page[models.ProductRevision], error)
+	FindRevision(ctx context.Context, productID uuid.UUID, version int) (*models.ProductRevision, error)
+	// Touch menaikkan Version tanpa mencatat revisi, dipakai kalau varian atau gambar
+	// (yang ikut di response GET produk) berubah. version selain 0 harus sama dengan
+	// Version tersimpan, kalau tidak ErrVersionConflict.
+	Touch(ctx context.Context, id uuid.UUID, version int64) error
+	// Each memanggil fn untuk setiap produk urut created_at, dibaca per batch
+	// supaya export katalog besar tidak dimuat ke memori sekaligus
+	Each(ctx context.Context, fn func(models.Product) error) error
+
+	// ApplyMovement mengubah stok secara atomik sesuai movement lalu mencatatnya
+	// (StockAfter dan ReservedAfter diisi), dan mengembalikan produk sesudah perubahan.
+	// Aman dipanggil paralel: stok tidak pernah negatif, kelebihan ditolak dengan ErrInsufficientStock.
+	ApplyMovement(ctx context.Context, movement *models.InventoryMovement) (*models.Product, error)
+	// Movements mengembalikan satu halaman riwayat stok produk sesuai MovementListSpec
+	Movements(ctx context.Context, productID uuid.UUID, q listing.Query) (listing.Page[models.InventoryMovement], error)
+}
+
+// SellerRepository menyimpan profil toko. ID seller sama dengan ID user pemiliknya;
+// Create dan Update mengembalikan ErrDuplicate kalau slug sudah dipakai.
+type SellerRepository interface {
+	Create(ctx context.Context, seller *models.Seller) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Seller, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Seller, error)
+	Update(ctx context.Context, seller *models.Seller) error
+}
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category *models.Category) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Category, error)
+	FindBySlug(ctx context.Context, slug string) (*models.Category, error)
+	// FindByIDs mengembalikan kategori yang ada saja, urut sort_order lalu name
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Category, error)
+	// List mengembalikan semua kategori, urut sort_order lalu name
+	List(ctx context.Context) ([]models.Category, error)
+	// Subtree mengembalikan id kategori beserta semua turunannya (rekursif)
+	Subtree(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	Update(ctx context.Context, category *models.Category) error
+	// Delete mengembalikan ErrInUse kalau kategori masih punya anak
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// VariantRepository menyimpan varian (SKU) produk. SKU unik di semua produk:
+// Create dan Update mengembalikan ErrDuplicate kalau SKU sudah dipakai.
+//
+// Create, Update dan Delete menaikkan Version produk induk dalam transaksi yang sama
+// (lihat ProductRepository.Touch); productVersion selain 0 harus sama dengan Version
+// produk, kalau tidak perubahan dibatalkan dengan ErrVersionConflict.
+type VariantRepository interface {
+	Create(ctx context.Context, variant *models.ProductVariant, productVersion int64) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.ProductVariant, error)
+	// ListByProduct mengembalikan semua varian produk, urut created_at lalu sku
+	ListByProduct(ctx context.Context, productID uuid.UUID) ([]models.ProductVariant, error)
+	Update(ctx context.Context, variant *models.ProductVariant, productVersion int64) error
+	Delete(ctx context.Context, productID, id uuid.UUID, productVersion int64) error
+}
+
+// ImageRepository menyimpan metadata gambar produk, urut position. Seperti
+// VariantRepository, perubahan menaikkan Version produk induk dengan productVersion.
+type ImageRepository interface {
+	// Create menaruh gambar di urutan terakhir (Position diisi)
+	Create(ctx context.Context, image *models.ProductImage, productVersion int64) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.ProductImage, error)
+	ListByProduct(ctx context.Context, productID uuid.UUID) ([]models.ProductImage, error)
+	// Reorder mengisi position sesuai urutan ids; ids harus berisi semua gambar produk
+	Reorder(ctx context.Context, productID uuid.UUID, ids []uuid.UUID, productVersion int64) error
+	Delete(ctx context.Context, productID, id uuid.UUID, productVersion int64) error
+}
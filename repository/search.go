@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"strings"
+
+	"learn_project/listing"
+	"learn_project/models"
+
+	"gorm.io/gorm"
+)
+
+// Pencarian ?q= produk (ProductListSpec.TextSearch).
+//
+// Di Postgres memakai kolom search_vector (migrasi 0004): websearch_to_tsquery
+// untuk sintaks ala mesin pencari ("kopi arabika", "kopi -bubuk", "\"kopi susu\""),
+// ditambah word similarity trigram pada nama supaya typo ("kopu") tetap ketemu.
+// Operator <% (word_similarity >= pg_trgm.word_similarity_threshold, default 0.6)
+// dipakai karena bisa memakai index idx_products_name_trgm.
+// Rank = ts_rank + word_similarity, highlight dari ts_headline.
+//
+// Driver lain (dan database Postgres tanpa search_vector, mis. hasil AutoMigrate)
+// memakai LIKE: setiap kata harus ada di name atau description, rank = jumlah
+// kata yang ada di name. Repository in-memory memakai aturan yang sama.
+
+// searchConfig adalah konfigurasi text search, harus sama dengan migrasi 0004
+const searchConfig = "'simple'"
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
+// hasFullText mengecek apakah database mendukung full-text search produk
+func hasFullText(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres" && db.Migrator().HasColumn(&models.Product{}, "search_vector")
+}
+
+// searchProducts membungkus tabel products menjadi subquery yang sudah tersaring ?q=
+// dan punya kolom rank dan highlight, dengan alias products supaya filter, soft delete
+// dan keyset listing tetap berlaku.
+func searchProducts(db *gorm.DB, q listing.Query, fullText bool) *gorm.DB {
+	inner := db.Session(&gorm.Session{NewDB: true}).Model(&models.Product{})
+	if q.IncludeDeleted {
+		inner = inner.Unscoped()
+	}
+
+	if fullText {
+		tsquery := "websearch_to_tsquery(" + searchConfig + ", ?)"
+		// Teks di-escape HTML sebelum ts_headline supaya hanya <mark> yang berupa tag
+		document := "replace(replace(replace(name || ' - ' || coalesce(description, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+		inner = inner.
+			Select("products.*, ts_rank(search_vector, "+tsquery+") + word_similarity(?, name) AS rank, "+
+				"ts_headline("+searchConfig+", "+document+", "+tsquery+", '"+headlineOptions+"') AS highlight",
+				q.Text, q.Text, q.Text).
+			Where("(search_vector @@ "+tsquery+" OR ? <% name)", q.Text, q.Text)
+	} else {
+		var rank []string
+		var args []any
+		for _, word := range searchWords(q.Text) {
+			pattern := listing.ContainsPattern(word)
+			rank = append(rank, "CASE WHEN LOWER(name) LIKE ? "+listing.LikeEscape+" THEN 1 ELSE 0 END")
+			args = append(args, pattern)
+			inner = inner.Where("(LOWER(name) LIKE ? "+listing.LikeEscape+" OR LOWER(COALESCE(description, '')) LIKE ? "+listing.LikeEscape+")", pattern, pattern)
+		}
+		inner = inner.Select("products.*, ("+strings.Join(rank, " + ")+") AS rank, '' AS highlight", args...)
+	}
+
+	return db.Table("(?) AS products", inner)
+}
+
+// searchWords memecah ?q= menjadi kata-kata lowercase untuk fallback LIKE
+func searchWords(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+// matchProduct adalah versi in-memory dari fallback LIKE: ok kalau semua kata
+// ditemukan, rank = jumlah kata yang ada di nama.
+func matchProduct(product models.Product, words []string) (rank float64, ok bool) {
+	name := strings.ToLower(product.Name)
+	description := strings.ToLower(product.Description)
+	for _, word := range words {
+		switch {
+		case strings.Contains(name, word):
+			rank++
+		case !strings.Contains(description, word):
+			return 0, false
+		}
+	}
+	return rank, true
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"learn_project/listing"
+	"learn_project/models"
+
+	"github.com/google/uuid"
+)
+
+// ProductListSpec adalah sort dan filter yang didukung GET /api/products
+var ProductListSpec = listing.Spec{
+	Fields: []listing.Field{
+		{Name: "name", Kind: listing.Text, Sortable: true},
+		{Name: "description", Kind: listing.Text, Contains: true},
+		{Name: "price", Kind: listing.Number, Sortable: true, Range: true},
+		{Name: "created_at", Param: "created", Kind: listing.Time, Sortable: true, Range: true},
+		{Name: "updated_at", Param: "updated", Kind: listing.Time, Sortable: true, Range: true},
+	},
+	DefaultSort: "created_at",
+	Search:      []string{"name"},
+	SoftDelete:  true,
+	TextSearch:  true,
+}
+
+// TrashListSpec adalah sort dan filter yang didukung GET /api/products/trash
+var TrashListSpec = listing.Spec{
+	Fields: []listing.Field{
+		{Name: "name", Kind: listing.Text, Sortable: true},
+		{Name: "price", Kind: listing.Number, Sortable: true, Range: true},
+		{Name: "deleted_at", Param: "deleted", Kind: listing.Time, Sortable: true, Range: true},
+	},
+	DefaultSort: "-deleted_at",
+	Search:      []string{"name"},
+}
+
+// BankListSpec adalah sort dan filter yang didukung GET /api/banks
+var BankListSpec = listing.Spec{
+	Fields: []listing.Field{
+		{Name: "bank_name", Kind: listing.Text, Sortable: true},
+		{Name: "nominal", Kind: listing.Number, Sortable: true, Range: true},
+		{Name: "created_at", Param: "created", Kind: listing.Time, Sortable: true, Range: true},
+	},
+	DefaultSort: "created_at",
+	Search:      []string{"bank_name", "account_no"},
+	SoftDelete:  true,
+}
+
+// MovementListSpec adalah sort dan filter yang didukung GET /api/products/:id/movements
+var MovementListSpec = listing.Spec{
+	Fields: []listing.Field{
+		{Name: "created_at", Param: "created", Kind: listing.Time, Sortable: true, Range: true},
+	},
+	DefaultSort: "-created_at",
+}
+
+// RevisionListSpec adalah sort dan filter yang didukung GET /api/products/:id/history
+var RevisionListSpec = listing.Spec{
+	Fields: []listing.Field{
+		{Name: "version", Kind: listing.Number, Sortable: true},
+		{Name: "created_at", Param: "created", Kind: listing.Time, Sortable: true, Range: true},
+	},
+	DefaultSort: "-version",
+}
+
+// productValue mengembalikan nilai kolom untuk sort, filter dan cursor
+func productValue(product models.Product, column string) any {
+	switch column {
+	case "name":
+		return product.Name
+	case "description":
+		return product.Description
+	case "price":
+		return product.Price
+	case "created_at":
+		return product.CreatedAt
+	case "updated_at":
+		return product.UpdatedAt
+	case "deleted_at":
+		return product.DeletedAt.Time
+	case listing.RankColumn:
+		return product.Rank
+	}
+	return nil
+}
+
+func productID(product models.Product) uuid.UUID {
+	return product.ID
+}
+
+func bankValue(bank models.Bank, column string) any {
+	switch column {
+	case "bank_name":
+		return bank.BankName
+	case "account_no":
+		return bank.AccountNo
+	case "nominal":
+		return bank.Nominal
+	case "created_at":
+		return bank.CreatedAt
+	}
+	return nil
+}
+
+func bankID(bank models.Bank) uuid.UUID {
+	return bank.ID
+}
+
+func movementValue(movement models.InventoryMovement, column string) any {
+	if column == "created_at" {
+		return movement.CreatedAt
+	}
+	return nil
+}
+
+func movementID(movement models.InventoryMovement) uuid.UUID {
+	return movement.ID
+}
+
+func revisionValue(revision models.ProductRevision, column string) any {
+	switch column {
+	case "version":
+		return float64(revision.Version)
+	case "created_at":
+		return revision.CreatedAt
+	}
+	return nil
+}
+
+func revisionID(revision models.ProductRevision) uuid.UUID {
+	return revision.ID
+}
@@ -1,36 +1,111 @@
 package routes
 
 import (
+	"database/sql"
+
 	"learn_project/controllers"
+	"learn_project/metrics"
 	"learn_project/middleware"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func SetupRoutes(app *fiber.App) {
+func SetupRoutes(app *fiber.App, h *controllers.Handler) {
+    // Health & build info (untuk orchestrator, tanpa autentikasi)
+    app.Get("/healthz", controllers.Healthz) // Liveness
+    app.Get("/readyz", h.Readyz)             // Readiness
+    app.Get("/version", controllers.Version) // Build info
+
     // Public routes (no authentication required)
-    app.Post("/register", controllers.Register) // Register a new user
-    app.Post("/login", controllers.Login)       // Login and get JWT token
+    app.Post("/register", h.Register)          // Register a new user
+    app.Post("/login", h.Login)                // Login and get JWT token
+    app.Get("/images/:id/:size", h.ServeImage) // Image file of a catalog product (original, medium, thumb)
+    app.Get("/sellers/:id", h.GetSeller)       // Seller profile by ID or slug
+
+    // Public catalog: hanya produk published yang sudah tayang, tanpa field internal.
+    // Cache-Control untuk CDN, ETag lemah dari isi response (If-None-Match -> 304).
+    catalogETag := etag.New(etag.Config{Weak: true})
+    catalog := app.Group("/catalog", catalogETag)
+    catalog.Get("/products", h.GetCatalogProducts)                     // Same query params as /api/products
+    catalog.Get("/products/:id", h.GetCatalogProduct)                  // With variants, images and price range
+    app.Get("/sellers/:id/products", catalogETag, h.GetSellerProducts) // Catalog products of a seller
 
     // Protected routes (require JWT authentication)
-    api := app.Group("/api", middleware.Protected()) // Group for protected routes
-    api.Get("/user", controllers.GetUser)            // Example protected route
+    api := app.Group("/api", middleware.Protected(h.JWT)) // Group for protected routes
+    api.Get("/user", h.GetUser)                           // Example protected route
 
 
     // Product routes
-    api.Post("/products", controllers.CreateProduct)   // Create a product
-    api.Get("/products", controllers.GetProducts)      // Get all products
-    api.Get("/products/:id", controllers.GetProduct)   // Get a single product
-    api.Put("/products/:id", controllers.UpdateProduct) // Update a product
-    api.Delete("/products/:id", controllers.DeleteProduct) // Delete a product
-
-    // Bank CRUD routes
-    api.Post("/bank", controllers.AddBank)          // Create a bank
-    api.Get("/banks", controllers.GetUserBanks)     // Get all user banks
-    api.Put("/bank/:id", controllers.UpdateBank)    // Update bank details
-    api.Delete("/bank/:id", controllers.DeleteBank) // Delete a bank
+    api.Post("/products/import", h.ImportProducts)      // Bulk upsert by SKU from CSV/NDJSON (?dry_run=true)
+    api.Get("/products/export", h.ExportProducts)       // Stream all products (?format=csv|ndjson)
+    api.Get("/products/trash", h.AdminOnly, h.GetTrash) // Soft-deleted products (admin)
+
+    // Seller (profil toko milik user yang login)
+    api.Get("/me/seller", h.GetMySeller)     // Own store profile
+    api.Put("/me/seller", h.SaveMySeller)    // Create or replace own store profile
+    api.Get("/me/products", h.GetMyProducts) // Products owned by the current user
+
+    // Perubahan produk hanya untuk seller pemiliknya atau admin (403 NOT_PRODUCT_OWNER)
+    // PUT, PATCH dan DELETE wajib If-Match dengan ETag dari GET (412 kalau sudah berubah)
+    api.Post("/products", h.CreateProduct)       // Create a product
+    api.Get("/products", h.GetProducts)          // Get all products
+    api.Get("/products/:id", h.GetProduct)       // Get a single product (ETag, If-None-Match)
+    api.Put("/products/:id", h.UpdateProduct)    // Replace a product (all fields)
+    api.Patch("/products/:id", h.PatchProduct)   // Merge Patch or JSON Patch
+    api.Delete("/products/:id", h.DeleteProduct) // Delete a product
+
+    // Product history (version per change) and trash
+    api.Get("/products/:id/history", h.GetProductHistory)            // Versions, newest first
+    api.Post("/products/:id/revert/:version", h.RevertProduct)       // Restore fields of a version as a new version
+    api.Post("/products/:id/restore", h.AdminOnly, h.RestoreProduct) // Undelete from trash (admin)
+
+    // Background jobs (owner or admin)
+    api.Get("/jobs/:id", h.GetJob) // Progress and result, e.g. large product imports
+
+    // Inventory
+    api.Post("/products/:id/movements", h.CreateMovement) // Receive, adjust, reserve, release, sell
+    api.Get("/products/:id/movements", h.GetMovements)    // Stock movement history
+
+    // Product variants (SKU); perubahan wajib If-Match dengan ETag produk
+    api.Post("/products/:id/variants", h.CreateVariant)              // Add a variant
+    api.Get("/products/:id/variants", h.GetVariants)                 // Get all variants of a product
+    api.Get("/products/:id/variants/:variantID", h.GetVariant)       // Get a single variant
+    api.Put("/products/:id/variants/:variantID", h.UpdateVariant)    // Update a variant
+    api.Delete("/products/:id/variants/:variantID", h.DeleteVariant) // Delete a variant
+
+    // Product images (multipart upload, urutan per produk); If-Match wajib seperti varian
+    api.Post("/products/:id/images", h.UploadImage)            // Upload an image
+    api.Get("/products/:id/images", h.GetImages)               // Get images in display order
+    api.Put("/products/:id/images", h.ReorderImages)           // Reorder images
+    api.Delete("/products/:id/images/:imageID", h.DeleteImage) // Delete an image
+    api.Get("/images/:id/:size", h.ServeProductImage)          // Image file of any status (owner or admin)
+
+    // Category routes (perubahan hanya untuk admin)
+    api.Get("/categories", h.GetCategories)                      // Flat list
+    api.Get("/categories/tree", h.GetCategoryTree)               // Nested tree
+    api.Get("/categories/:id", h.GetCategory)                    // By ID or slug
+    api.Post("/categories", h.AdminOnly, h.CreateCategory)       // Create a category
+    api.Put("/categories/:id", h.AdminOnly, h.UpdateCategory)    // Update a category
+    api.Delete("/categories/:id", h.AdminOnly, h.DeleteCategory) // Delete a category
+
+    // Bank CRUD routes (PUT, PATCH dan DELETE wajib If-Match dengan ETag dari GET)
+    api.Post("/bank", h.AddBank)          // Create a bank
+    api.Get("/banks", h.GetUserBanks)     // Get all user banks
+    api.Get("/bank/:id", h.GetBank)       // Get a single bank (ETag, If-None-Match)
+    api.Put("/bank/:id", h.UpdateBank)    // Replace bank details
+    api.Patch("/bank/:id", h.PatchBank)   // Merge Patch or JSON Patch
+    api.Delete("/bank/:id", h.DeleteBank) // Delete a bank
 
     // Money management
-    api.Put("/bank/:id/add-money", controllers.AddMoney) // Add money to bank
+    api.Put("/bank/:id/add-money", h.AddMoney) // Add money to bank (atomic, If-Match optional)
    
-}
\ No newline at end of file
+}
+
+// SetupAdminRoutes mendaftarkan endpoint operasional di admin app (port terpisah)
+func SetupAdminRoutes(admin *fiber.App, db *sql.DB) {
+    admin.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))) // Prometheus
+    admin.Get("/db/stats", controllers.PoolStats(db))                                                          // Connection pool
+}
@@ -1,28 +1,222 @@
 package main
 
 import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"learn_project/config"
+	"learn_project/controllers"
 	"learn_project/database"
+	"learn_project/events"
+	"learn_project/health"
+	"learn_project/i18n"
+	"learn_project/jobs"
+	"learn_project/logging"
+	"learn_project/metrics"
+	"learn_project/middleware"
+	"learn_project/migrations"
+	"learn_project/repository"
 	"learn_project/routes"
+	"learn_project/storage"
+	"learn_project/tracing"
+	"learn_project/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 )
 
 func main() {
-    // Load environment variables from .env file
-    if err := godotenv.Load(); err != nil {
-        panic("Error loading .env file")
-    }
+	// Subcommand `migrate` (up, down, status, create)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
+	// Load and validate configuration (env, .env, optional config.yaml)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Logger JSON untuk seluruh aplikasi; paket log standar juga diarahkan ke sini
+	logger := logging.New(cfg.Log)
+	slog.SetDefault(logger)
+
+	// Bahasa default untuk pesan API, dipakai kalau Accept-Language tidak cocok
+	if err := i18n.SetDefaultLanguage(cfg.App.Language); err != nil {
+		log.Fatal(err)
+	}
+
+	// OpenTelemetry tracing (none/stdout/otlp)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize the database
+	database.Connect(cfg.Database, cfg.Log)
+
+	migrationFS, err := migrations.FS(cfg.Database.Driver)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	migrator, err := database.NewMigrator(database.DB, migrationFS)
+	if err != nil {
+		log.Fatal("❌ Gagal membaca file migrasi:", err)
+	}
+
+	// Create a new Fiber app
+	// ErrorHandler memetakan error bertipe dari controller ke kode error yang stabil
+	app := fiber.New(fiber.Config{
+		ErrorHandler: utils.ErrorHandler,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+		BodyLimit:    cfg.Server.BodyLimit,
+	})
+
+	// Request ID + access log, trace span, lalu metric Prometheus untuk semua route.
+	// Recover paling dalam: panic di handler menjadi 500 yang tetap tercatat di log,
+	// trace dan metric, bukan mematikan proses.
+	app.Use(middleware.RequestLogger(logger))
+	app.Use(tracing.Middleware())
+	app.Use(metrics.Middleware())
+	app.Use(recover.New(recover.Config{EnableStackTrace: true}))
+
+	// Event domain dikirim ke webhook kalau WEBHOOK_URL diisi, selain itu hanya dicatat di log
+	var publisher events.Publisher = events.LogPublisher{}
+	var webhook *events.Webhook
+	if cfg.Webhook.URL != "" {
+		webhook = events.NewWebhook(cfg.Webhook)
+		publisher = webhook
+	}
+
+	// Gambar produk disimpan di filesystem lokal atau bucket S3 (STORAGE_DRIVER)
+	blobs, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Handler memakai repository GORM; readiness mengecek database dan migrasi
+	// (cek migrasi dilewati kalau skema diurus AutoMigrate)
+	handler := &controllers.Handler{
+		Users:           repository.NewGormUserRepository(database.DB),
+		Banks:           repository.NewGormBankRepository(database.DB),
+		Products:        repository.NewGormProductRepository(database.DB),
+		Categories:      repository.NewGormCategoryRepository(database.DB),
+		Variants:        repository.NewGormVariantRepository(database.DB),
+		Images:          repository.NewGormImageRepository(database.DB),
+		Sellers:         repository.NewGormSellerRepository(database.DB),
+		JWT:             utils.NewJWTManager(cfg.JWT),
+		Blobs:           blobs,
+		MaxImageSize:    cfg.Storage.MaxImageSize,
+		Events:          publisher,
+		Jobs:            jobs.NewManager(),
+		ReadinessChecks: []health.Check{database.PingCheck(database.DB)},
+	}
+	if !cfg.Database.AutoMigrate {
+		handler.ReadinessChecks = append(handler.ReadinessChecks, database.MigrationsCheck(migrator))
+	}
+
+	// Set up routes
+	routes.SetupRoutes(app, handler)
+
+	// Admin app (mis. /metrics, /db/stats) di port terpisah yang tidak dibuka ke publik
+	var admin *fiber.App
+	if cfg.Admin.Enabled {
+		sqlDB, err := database.DB.DB()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		admin = fiber.New(fiber.Config{
+			ErrorHandler:          utils.ErrorHandler,
+			DisableStartupMessage: true,
+		})
+		routes.SetupAdminRoutes(admin, sqlDB)
+	}
+
+	// Tangkap SIGINT/SIGTERM supaya deploy tidak memutus request yang sedang berjalan
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Start the server
+	listenErr := make(chan error, 2)
+	if admin != nil {
+		go func() {
+			slog.Info("Admin server berjalan", "addr", cfg.Admin.Addr())
+			listenErr <- admin.Listen(cfg.Admin.Addr())
+		}()
+	}
+	go func() {
+		if cfg.Server.TLSEnabled() {
+			listenErr <- app.ListenTLS(cfg.Server.Addr(), cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+		} else {
+			listenErr <- app.Listen(cfg.Server.Addr())
+		}
+	}()
+
+	select {
+	case err := <-listenErr:
+		if err != nil {
+			slog.Error("❌ Server gagal berjalan", "error", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+	}
+
+	shutdown(app, admin, handler.Jobs, cfg.Server)
+
+	// Kirim sisa event webhook dan span yang masih di buffer
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if webhook != nil {
+		if err := webhook.Close(flushCtx); err != nil {
+			slog.Error("❌ Gagal mengirim sisa event webhook", "error", err)
+		}
+	}
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("❌ Gagal mengirim sisa trace", "error", err)
+	}
+}
+
+// shutdown menandai readiness gagal, menunggu ShutdownDelay supaya load balancer
+// berhenti mengirim traffic, berhenti menerima koneksi baru, menunggu request yang
+// sedang berjalan sampai batas ShutdownTimeout, menunggu job background, lalu menutup
+// admin app dan connection pool database.
+func shutdown(app, admin *fiber.App, background *jobs.Manager, cfg config.ServerConfig) {
+	slog.Info("⏳ Menghentikan server...")
+
+	health.SetShuttingDown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		slog.Error("❌ Gagal menunggu request selesai", "error", err)
+	}
 
-    // Initialize the database
-    database.Connect()
+	// Job yang belum selesai saat batas waktu habis dibatalkan (mis. import berhenti di tengah)
+	if err := background.Close(ctx); err != nil {
+		slog.Error("❌ Job background dibatalkan", "error", err)
+	}
 
-    // Create a new Fiber app
-    app := fiber.New()
+	// Admin app dimatikan terakhir supaya metric tetap bisa di-scrape selama drain
+	if admin != nil {
+		if err := admin.ShutdownWithContext(ctx); err != nil {
+			slog.Error("❌ Gagal menghentikan admin server", "error", err)
+		}
+	}
 
-    // Set up routes
-    routes.SetupRoutes(app)
+	if err := database.Close(); err != nil {
+		slog.Error("❌ Gagal menutup koneksi database", "error", err)
+	}
 
-    // Start the server
-    app.Listen(":3000")
-}
\ No newline at end of file
+	slog.Info("✅ Server berhenti")
+}
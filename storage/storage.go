@@ -0,0 +1,53 @@
+// Package storage menyimpan file biner (mis. gambar produk) di luar database.
+// BlobStore punya implementasi filesystem lokal (development, satu server) dan
+// S3-compatible (AWS S3, MinIO, R2, ...) untuk production.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"learn_project/config"
+)
+
+// ErrNotFound dikembalikan Get kalau key tidak ada
+var ErrNotFound = errors.New("storage: blob not found")
+
+// ErrInvalidKey dikembalikan kalau key bukan path relatif yang bersih (mis. mengandung "..")
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// Object adalah isi blob yang dibaca Get; Body wajib ditutup pemanggil
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+}
+
+// BlobStore menyimpan blob dengan key berbentuk path, mis. "products/<id>/<image>/thumb.jpg"
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	// Delete tidak mengembalikan error kalau key memang tidak ada
+	Delete(ctx context.Context, key string) error
+}
+
+// New membuat BlobStore sesuai STORAGE_DRIVER
+func New(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocal(cfg.Path)
+	case "s3":
+		return NewS3(cfg.S3), nil
+	}
+	return nil, fmt.Errorf("storage driver %q tidak dikenal", cfg.Driver)
+}
+
+func validKey(key string) error {
+	if !fs.ValidPath(key) || key == "." {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"learn_project/config"
+)
+
+func testStore(t *testing.T, store BlobStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "products/a/thumb.jpg", []byte("isi gambar"), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	object, err := store.Get(ctx, "products/a/thumb.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(object.Body)
+	object.Body.Close()
+	if string(data) != "isi gambar" || object.Size != int64(len(data)) || object.ContentType != "image/jpeg" {
+		t.Fatalf("object = %q, size %d, type %q", data, object.Size, object.ContentType)
+	}
+
+	if err := store.Delete(ctx, "products/a/thumb.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "products/a/thumb.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get setelah Delete: err = %v, want ErrNotFound", err)
+	}
+	// Menghapus key yang tidak ada bukan error
+	if err := store.Delete(ctx, "products/a/thumb.jpg"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../rahasia", "/etc/passwd", "a//b", ""} {
+		if err := store.Put(ctx, key, nil, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("Put(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestLocal(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+// fakeS3 adalah pengganti bucket S3 (path-style) di memori yang mengecek header SigV4
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		!strings.Contains(auth, "/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/produk/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: map[string][]byte{}, types: map[string]string{}})
+	defer server.Close()
+
+	store := NewS3(config.S3Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    "produk",
+		AccessKey: "AKID",
+		SecretKey: "rahasia",
+		PathStyle: true,
+	})
+	testStore(t, store)
+
+	// Error dari server diteruskan
+	wrong := NewS3(config.S3Config{Endpoint: server.URL, Region: "eu-west-1", Bucket: "produk", AccessKey: "AKID", PathStyle: true})
+	if err := wrong.Put(context.Background(), "a.jpg", nil, ""); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("err = %v, want 403", err)
+	}
+}
+
+func TestS3ObjectURL(t *testing.T) {
+	store := NewS3(config.S3Config{Endpoint: "https://s3.ap-southeast-1.amazonaws.com", Bucket: "produk"})
+	u, err := store.objectURL("products/a b/thumb.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.String(); got != "https://produk.s3.ap-southeast-1.amazonaws.com/products/a%20b/thumb.jpg" {
+		t.Fatalf("url = %s", got)
+	}
+}
@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"fmt"
+
+	"learn_project/httperr"
+	"learn_project/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier membuat header fasthttp bisa dibaca oleh propagator OpenTelemetry
+type headerCarrier struct {
+	header *fasthttp.RequestHeader
+}
+
+func (h headerCarrier) Get(key string) string {
+	return string(h.header.Peek(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, h.header.Len())
+	h.header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Middleware membuat server span untuk setiap request, melanjutkan trace dari
+// header traceparent/tracestate (W3C), dan menaruh span di UserContext supaya
+// query GORM dan span lain di controller menjadi child span.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{&c.Request().Header})
+
+		ctx, span := Tracer().Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.ClientAddress(c.IP()),
+				semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		// Tambahkan trace_id ke logger request supaya log bisa dicocokkan dengan trace
+		if span.SpanContext().IsValid() {
+			logger := logging.FromContext(ctx).With("trace_id", span.SpanContext().TraceID().String())
+			ctx = logging.WithLogger(ctx, logger)
+		}
+		c.SetUserContext(ctx)
+
+		chainErr := httperr.Respond(c, c.Next())
+
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		span.SetName(fmt.Sprintf("%s %s", c.Method(), route))
+		span.SetAttributes(
+			semconv.HTTPRoute(route),
+			semconv.HTTPResponseStatusCode(status),
+		)
+		if requestID, ok := c.Locals("request_id").(string); ok {
+			span.SetAttributes(attribute.String("http.request.header.x-request-id", requestID))
+		}
+		// Error 4xx adalah kesalahan client, hanya 5xx yang menandai span gagal
+		if status >= fiber.StatusInternalServerError {
+			if chainErr != nil {
+				span.RecordError(chainErr)
+			}
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		return chainErr
+	}
+}
@@ -0,0 +1,62 @@
+package utils_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"learn_project/i18n"
+)
+
+// declaredCodes mengumpulkan semua konstanta bertipe typeName di package utils,
+// supaya kode baru otomatis ikut dicek tanpa daftar yang harus diperbarui manual
+func declaredCodes(t *testing.T, typeName string) []string {
+	t.Helper()
+
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var codes []string
+	for _, file := range pkgs["utils"].Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != typeName {
+					continue
+				}
+				for _, v := range value.Values {
+					lit, ok := v.(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING {
+						t.Fatalf("%s %v bukan string literal", typeName, value.Names)
+					}
+					code, _ := strconv.Unquote(lit.Value)
+					codes = append(codes, code)
+				}
+			}
+		}
+	}
+	if len(codes) == 0 {
+		t.Fatalf("tidak ada konstanta %s", typeName)
+	}
+	return codes
+}
+
+func TestCodesTranslated(t *testing.T) {
+	for _, typeName := range []string{"ErrorCode", "SuccessCode"} {
+		codes := declaredCodes(t, typeName)
+		for _, lang := range i18n.Languages() {
+			for _, code := range codes {
+				if _, ok := i18n.Lookup(lang, code); !ok {
+					t.Errorf("%s %s tidak punya pesan di bundle %q", typeName, code, lang)
+				}
+			}
+		}
+	}
+}
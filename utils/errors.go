@@ -0,0 +1,342 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+
+	"learn_project/httperr"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorCode adalah kode error yang stabil dan bisa dibaca mesin.
+// Client sebaiknya mencocokkan kode ini, bukan teks message.
+type ErrorCode string
+
+const (
+	// Umum
+	CodeBadRequest           ErrorCode = "BAD_REQUEST"
+	CodeInvalidInput         ErrorCode = "INVALID_INPUT"
+	CodeUnauthorized         ErrorCode = "UNAUTHORIZED"
+	CodeForbidden            ErrorCode = "FORBIDDEN"
+	CodeNotFound             ErrorCode = "NOT_FOUND"
+	CodeMethodNotAllowed     ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeRequestTooLarge      ErrorCode = "REQUEST_TOO_LARGE"
+	CodeTooManyRequests      ErrorCode = "TOO_MANY_REQUESTS"
+	CodeServiceUnavailable   ErrorCode = "SERVICE_UNAVAILABLE"
+	CodeInternal             ErrorCode = "INTERNAL_ERROR"
+	CodeInvalidPagination    ErrorCode = "INVALID_PAGINATION"
+	CodeInvalidCursor        ErrorCode = "INVALID_CURSOR"
+	CodeInvalidSort          ErrorCode = "INVALID_SORT"
+	CodeInvalidFilter        ErrorCode = "INVALID_FILTER"
+	CodeInvalidPatch         ErrorCode = "INVALID_PATCH"
+	CodePatchTestFailed      ErrorCode = "PATCH_TEST_FAILED"
+	CodeUnsupportedPatch     ErrorCode = "UNSUPPORTED_PATCH_FORMAT"
+	CodePreconditionFailed   ErrorCode = "PRECONDITION_FAILED"
+	CodePreconditionRequired ErrorCode = "PRECONDITION_REQUIRED"
+
+	// Auth & user
+	CodeMissingFields         ErrorCode = "MISSING_FIELDS"
+	CodeEmailInUse            ErrorCode = "EMAIL_IN_USE"
+	CodeInvalidCredentials    ErrorCode = "INVALID_CREDENTIALS"
+	CodeInvalidToken          ErrorCode = "INVALID_TOKEN"
+	CodePasswordHashFailed    ErrorCode = "PASSWORD_HASH_FAILED"
+	CodeTokenGenerationFailed ErrorCode = "TOKEN_GENERATION_FAILED"
+	CodeRefreshTokenFailed    ErrorCode = "REFRESH_TOKEN_GENERATION_FAILED"
+	CodeUserNotFound          ErrorCode = "USER_NOT_FOUND"
+	CodeUserCreateFailed      ErrorCode = "USER_CREATE_FAILED"
+
+	// Bank
+	CodeBankNotFound        ErrorCode = "BANK_NOT_FOUND"
+	CodeBankFieldsRequired  ErrorCode = "BANK_NAME_AND_ACCOUNT_REQUIRED"
+	CodeAccountNoInUse      ErrorCode = "ACCOUNT_NO_IN_USE"
+	CodeInsufficientFunds   ErrorCode = "INSUFFICIENT_FUNDS"
+	CodeInvalidCurrency     ErrorCode = "INVALID_CURRENCY"
+	CodeBankCreateFailed    ErrorCode = "BANK_CREATE_FAILED"
+	CodeBankListFailed      ErrorCode = "BANK_LIST_FAILED"
+	CodeBankCountFailed     ErrorCode = "BANK_COUNT_FAILED"
+	CodeBankUpdateFailed    ErrorCode = "BANK_UPDATE_FAILED"
+	CodeBankDeleteFailed    ErrorCode = "BANK_DELETE_FAILED"
+	CodeBalanceUpdateFailed ErrorCode = "BALANCE_UPDATE_FAILED"
+
+	// Product
+	CodeProductNameAndPriceRequired ErrorCode = "PRODUCT_NAME_AND_PRICE_REQUIRED"
+	CodeProductNotFound             ErrorCode = "PRODUCT_NOT_FOUND"
+	CodeInvalidPrice                ErrorCode = "INVALID_PRICE"
+	CodeInvalidProductStatus        ErrorCode = "INVALID_PRODUCT_STATUS"
+	CodeProductCreateFailed         ErrorCode = "PRODUCT_CREATE_FAILED"
+	CodeProductListFailed           ErrorCode = "PRODUCT_LIST_FAILED"
+	CodeProductCountFailed          ErrorCode = "PRODUCT_COUNT_FAILED"
+	CodeProductUpdateFailed         ErrorCode = "PRODUCT_UPDATE_FAILED"
+	CodeProductDeleteFailed         ErrorCode = "PRODUCT_DELETE_FAILED"
+	CodeUnknownCategory             ErrorCode = "UNKNOWN_CATEGORY"
+	CodeNotProductOwner             ErrorCode = "NOT_PRODUCT_OWNER"
+
+	// Seller (profil toko)
+	CodeSellerNotFound    ErrorCode = "SELLER_NOT_FOUND"
+	CodeStoreNameRequired ErrorCode = "STORE_NAME_REQUIRED"
+	CodeSellerListFailed  ErrorCode = "SELLER_LIST_FAILED"
+	CodeSellerSaveFailed  ErrorCode = "SELLER_SAVE_FAILED"
+
+	// Inventory
+	CodeInvalidMovementType    ErrorCode = "INVALID_MOVEMENT_TYPE"
+	CodeInvalidQuantity        ErrorCode = "INVALID_QUANTITY"
+	CodeMovementReasonRequired ErrorCode = "MOVEMENT_REASON_REQUIRED"
+	CodeInvalidStockThreshold  ErrorCode = "INVALID_STOCK_THRESHOLD"
+	CodeInsufficientStock      ErrorCode = "INSUFFICIENT_STOCK"
+	CodeStockUpdateFailed      ErrorCode = "STOCK_UPDATE_FAILED"
+	CodeMovementListFailed     ErrorCode = "MOVEMENT_LIST_FAILED"
+
+	// Category
+	CodeCategoryNameRequired  ErrorCode = "CATEGORY_NAME_REQUIRED"
+	CodeInvalidSlug           ErrorCode = "INVALID_SLUG"
+	CodeSlugInUse             ErrorCode = "SLUG_IN_USE"
+	CodeInvalidParentCategory ErrorCode = "INVALID_PARENT_CATEGORY"
+	CodeCategoryNotFound      ErrorCode = "CATEGORY_NOT_FOUND"
+	CodeCategoryHasChildren   ErrorCode = "CATEGORY_HAS_CHILDREN"
+	CodeCategoryCreateFailed  ErrorCode = "CATEGORY_CREATE_FAILED"
+	CodeCategoryListFailed    ErrorCode = "CATEGORY_LIST_FAILED"
+	CodeCategoryUpdateFailed  ErrorCode = "CATEGORY_UPDATE_FAILED"
+	CodeCategoryDeleteFailed  ErrorCode = "CATEGORY_DELETE_FAILED"
+
+	// Variant
+	CodeInvalidProductOptions    ErrorCode = "INVALID_PRODUCT_OPTIONS"
+	CodeOptionsInUse             ErrorCode = "OPTIONS_IN_USE"
+	CodeInvalidSKU               ErrorCode = "INVALID_SKU"
+	CodeSKUInUse                 ErrorCode = "SKU_IN_USE"
+	CodeInvalidVariant           ErrorCode = "INVALID_VARIANT"
+	CodeInvalidVariantAttributes ErrorCode = "INVALID_VARIANT_ATTRIBUTES"
+	CodeDuplicateVariant         ErrorCode = "DUPLICATE_VARIANT"
+	CodeVariantNotFound          ErrorCode = "VARIANT_NOT_FOUND"
+	CodeVariantCreateFailed      ErrorCode = "VARIANT_CREATE_FAILED"
+	CodeVariantListFailed        ErrorCode = "VARIANT_LIST_FAILED"
+	CodeVariantUpdateFailed      ErrorCode = "VARIANT_UPDATE_FAILED"
+	CodeVariantDeleteFailed      ErrorCode = "VARIANT_DELETE_FAILED"
+
+	// Image
+	CodeImageRequired      ErrorCode = "IMAGE_REQUIRED"
+	CodeImageTooLarge      ErrorCode = "IMAGE_TOO_LARGE"
+	CodeUnsupportedImage   ErrorCode = "UNSUPPORTED_IMAGE"
+	CodeInvalidImage       ErrorCode = "INVALID_IMAGE"
+	CodeInvalidImageOrder  ErrorCode = "INVALID_IMAGE_ORDER"
+	CodeImageNotFound      ErrorCode = "IMAGE_NOT_FOUND"
+	CodeImageUploadFailed  ErrorCode = "IMAGE_UPLOAD_FAILED"
+	CodeImageListFailed    ErrorCode = "IMAGE_LIST_FAILED"
+	CodeImageReorderFailed ErrorCode = "IMAGE_REORDER_FAILED"
+	CodeImageDeleteFailed  ErrorCode = "IMAGE_DELETE_FAILED"
+
+	// Import/export katalog
+	CodeUnsupportedImportFormat ErrorCode = "UNSUPPORTED_IMPORT_FORMAT"
+	CodeInvalidImportFile       ErrorCode = "INVALID_IMPORT_FILE"
+	CodeInvalidExportFormat     ErrorCode = "INVALID_EXPORT_FORMAT"
+	CodeInvalidValue            ErrorCode = "INVALID_VALUE" // hanya di laporan baris import
+	CodeDuplicateRow            ErrorCode = "DUPLICATE_ROW" // hanya di laporan baris import
+	CodeImportFailed            ErrorCode = "IMPORT_FAILED"
+	CodeJobNotFound             ErrorCode = "JOB_NOT_FOUND"
+
+	// Riwayat dan trash produk
+	CodeRevisionNotFound     ErrorCode = "REVISION_NOT_FOUND"
+	CodeHistoryListFailed    ErrorCode = "HISTORY_LIST_FAILED"
+	CodeProductRevertFailed  ErrorCode = "PRODUCT_REVERT_FAILED"
+	CodeProductRestoreFailed ErrorCode = "PRODUCT_RESTORE_FAILED"
+)
+
+// AppError adalah error bertipe yang dikembalikan controller.
+// ErrorHandler memetakan error ini menjadi response JSON dengan kode yang stabil.
+type AppError struct {
+	Status  int
+	Code    ErrorCode
+	Message string
+	Data    interface{}
+	Err     error // penyebab asli, tidak pernah dikirim ke client
+}
+
+// NewError membuat AppError baru
+func NewError(status int, code ErrorCode, message string) *AppError {
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is membuat errors.Is cocok berdasarkan kode, sehingga salinan dari Wrap/WithData
+// tetap dianggap sama dengan error aslinya.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap mengembalikan salinan error dengan penyebab aslinya
+func (e *AppError) Wrap(err error) *AppError {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+// WithData mengembalikan salinan error dengan data tambahan untuk client
+func (e *AppError) WithData(data interface{}) *AppError {
+	clone := *e
+	clone.Data = data
+	return &clone
+}
+
+// Katalog error yang dipakai controller dan middleware
+var (
+	ErrInvalidInput         = NewError(fiber.StatusBadRequest, CodeInvalidInput, "Invalid input")
+	ErrUnauthorized         = NewError(fiber.StatusUnauthorized, CodeUnauthorized, "Unauthorized")
+	ErrInvalidToken         = NewError(fiber.StatusUnauthorized, CodeInvalidToken, "Invalid token")
+	ErrInternal             = NewError(fiber.StatusInternalServerError, CodeInternal, "Internal server error")
+	ErrInvalidPagination    = NewError(fiber.StatusBadRequest, CodeInvalidPagination, "Page must be at least 1 and limit between 1 and 100")
+	ErrInvalidCursor        = NewError(fiber.StatusBadRequest, CodeInvalidCursor, "Invalid or expired cursor")
+	ErrInvalidSort          = NewError(fiber.StatusBadRequest, CodeInvalidSort, "Invalid sort parameter")
+	ErrInvalidFilter        = NewError(fiber.StatusBadRequest, CodeInvalidFilter, "Invalid filter parameter")
+	ErrForbidden            = NewError(fiber.StatusForbidden, CodeForbidden, "Forbidden")
+	ErrInvalidPatch         = NewError(fiber.StatusBadRequest, CodeInvalidPatch, "Patch could not be applied")
+	ErrPatchTestFailed      = NewError(fiber.StatusConflict, CodePatchTestFailed, "Patch test operation failed")
+	ErrUnsupportedPatch     = NewError(fiber.StatusUnsupportedMediaType, CodeUnsupportedPatch, "PATCH body must be application/merge-patch+json or application/json-patch+json")
+	ErrPreconditionFailed   = NewError(fiber.StatusPreconditionFailed, CodePreconditionFailed, "The resource was changed by another request; fetch it again and retry with the new ETag")
+	ErrPreconditionRequired = NewError(fiber.StatusPreconditionRequired, CodePreconditionRequired, "An If-Match header with the current ETag is required")
+	ErrMissingFields        = NewError(fiber.StatusBadRequest, CodeMissingFields, "All fields are required")
+	ErrEmailInUse           = NewError(fiber.StatusConflict, CodeEmailInUse, "Email already in use")
+	ErrInvalidCredentials   = NewError(fiber.StatusUnauthorized, CodeInvalidCredentials, "Invalid credentials")
+	ErrPasswordHash         = NewError(fiber.StatusInternalServerError, CodePasswordHashFailed, "Could not hash password")
+	ErrTokenGeneration      = NewError(fiber.StatusInternalServerError, CodeTokenGenerationFailed, "Could not generate token")
+	ErrRefreshToken         = NewError(fiber.StatusInternalServerError, CodeRefreshTokenFailed, "Could not generate refresh token")
+	ErrUserNotFound         = NewError(fiber.StatusNotFound, CodeUserNotFound, "User not found")
+	ErrUserCreate           = NewError(fiber.StatusInternalServerError, CodeUserCreateFailed, "Could not create user")
+
+	ErrBankNotFound       = NewError(fiber.StatusNotFound, CodeBankNotFound, "Bank not found")
+	ErrBankFieldsRequired = NewError(fiber.StatusBadRequest, CodeBankFieldsRequired, "Bank name and account number are required")
+	ErrAccountNoInUse     = NewError(fiber.StatusConflict, CodeAccountNoInUse, "Account number already in use")
+	ErrInsufficientFunds  = NewError(fiber.StatusUnprocessableEntity, CodeInsufficientFunds, "Insufficient funds")
+	ErrInvalidCurrency    = NewError(fiber.StatusBadRequest, CodeInvalidCurrency, "Currency must be a 3-letter ISO 4217 code")
+	ErrBankCreate         = NewError(fiber.StatusInternalServerError, CodeBankCreateFailed, "Could not add bank")
+	ErrBankList           = NewError(fiber.StatusInternalServerError, CodeBankListFailed, "Could not fetch banks")
+	ErrBankCount          = NewError(fiber.StatusInternalServerError, CodeBankCountFailed, "Could not fetch bank count")
+	ErrBankUpdate         = NewError(fiber.StatusInternalServerError, CodeBankUpdateFailed, "Could not update bank")
+	ErrBankDelete         = NewError(fiber.StatusInternalServerError, CodeBankDeleteFailed, "Could not delete bank")
+	ErrBalanceUpdate      = NewError(fiber.StatusInternalServerError, CodeBalanceUpdateFailed, "Could not update balance")
+
+	ErrProductNameAndPriceRequired = NewError(fiber.StatusBadRequest, CodeProductNameAndPriceRequired, "Name and price are required")
+	ErrProductNotFound             = NewError(fiber.StatusNotFound, CodeProductNotFound, "Product not found")
+	ErrInvalidPrice                = NewError(fiber.StatusBadRequest, CodeInvalidPrice, "Price must not be negative")
+	ErrInvalidProductStatus        = NewError(fiber.StatusBadRequest, CodeInvalidProductStatus, "Status must be draft, published or archived")
+	ErrProductCreate               = NewError(fiber.StatusInternalServerError, CodeProductCreateFailed, "Could not create product")
+	ErrProductList                 = NewError(fiber.StatusInternalServerError, CodeProductListFailed, "Could not fetch products")
+	ErrProductCount                = NewError(fiber.StatusInternalServerError, CodeProductCountFailed, "Could not fetch product count")
+	ErrProductUpdate               = NewError(fiber.StatusInternalServerError, CodeProductUpdateFailed, "Could not update product")
+	ErrProductDelete               = NewError(fiber.StatusInternalServerError, CodeProductDeleteFailed, "Could not delete product")
+	ErrUnknownCategory             = NewError(fiber.StatusBadRequest, CodeUnknownCategory, "One or more categories do not exist")
+	ErrNotProductOwner             = NewError(fiber.StatusForbidden, CodeNotProductOwner, "Only the seller of this product or an admin can change it")
+
+	ErrSellerNotFound    = NewError(fiber.StatusNotFound, CodeSellerNotFound, "Seller not found")
+	ErrStoreNameRequired = NewError(fiber.StatusBadRequest, CodeStoreNameRequired, "Store name is required (max 100 characters)")
+	ErrSellerList        = NewError(fiber.StatusInternalServerError, CodeSellerListFailed, "Could not fetch seller")
+	ErrSellerSave        = NewError(fiber.StatusInternalServerError, CodeSellerSaveFailed, "Could not save seller profile")
+
+	ErrInvalidMovementType    = NewError(fiber.StatusBadRequest, CodeInvalidMovementType, "Movement type must be one of receive, adjust, reserve, release, sell")
+	ErrInvalidQuantity        = NewError(fiber.StatusBadRequest, CodeInvalidQuantity, "Quantity must be greater than 0 (non-zero for adjust)")
+	ErrMovementReasonRequired = NewError(fiber.StatusBadRequest, CodeMovementReasonRequired, "A reason is required for stock adjustments")
+	ErrInvalidStockThreshold  = NewError(fiber.StatusBadRequest, CodeInvalidStockThreshold, "Low stock threshold must not be negative")
+	ErrInsufficientStock      = NewError(fiber.StatusUnprocessableEntity, CodeInsufficientStock, "Insufficient stock")
+	ErrStockUpdate            = NewError(fiber.StatusInternalServerError, CodeStockUpdateFailed, "Could not update stock")
+	ErrMovementList           = NewError(fiber.StatusInternalServerError, CodeMovementListFailed, "Could not fetch stock movements")
+
+	ErrCategoryNameRequired  = NewError(fiber.StatusBadRequest, CodeCategoryNameRequired, "Category name is required")
+	ErrInvalidSlug           = NewError(fiber.StatusBadRequest, CodeInvalidSlug, "Slug may only contain lowercase letters, numbers and dashes")
+	ErrSlugInUse             = NewError(fiber.StatusConflict, CodeSlugInUse, "Slug already in use")
+	ErrInvalidParentCategory = NewError(fiber.StatusBadRequest, CodeInvalidParentCategory, "Parent category does not exist or is a subcategory of this category")
+	ErrCategoryNotFound      = NewError(fiber.StatusNotFound, CodeCategoryNotFound, "Category not found")
+	ErrCategoryHasChildren   = NewError(fiber.StatusConflict, CodeCategoryHasChildren, "Category still has subcategories")
+	ErrCategoryCreate        = NewError(fiber.StatusInternalServerError, CodeCategoryCreateFailed, "Could not create category")
+	ErrCategoryList          = NewError(fiber.StatusInternalServerError, CodeCategoryListFailed, "Could not fetch categories")
+	ErrCategoryUpdate        = NewError(fiber.StatusInternalServerError, CodeCategoryUpdateFailed, "Could not update category")
+	ErrCategoryDelete        = NewError(fiber.StatusInternalServerError, CodeCategoryDeleteFailed, "Could not delete category")
+
+	ErrInvalidProductOptions    = NewError(fiber.StatusBadRequest, CodeInvalidProductOptions, "Each option needs a unique name and at least one unique value")
+	ErrOptionsInUse             = NewError(fiber.StatusConflict, CodeOptionsInUse, "Existing variants do not match the new options")
+	ErrInvalidSKU               = NewError(fiber.StatusBadRequest, CodeInvalidSKU, "SKU must be 1-64 letters, digits, dots, dashes or underscores")
+	ErrSKUInUse                 = NewError(fiber.StatusConflict, CodeSKUInUse, "SKU is already in use")
+	ErrInvalidVariant           = NewError(fiber.StatusBadRequest, CodeInvalidVariant, "Variant price must be greater than 0 and stock must not be negative")
+	ErrInvalidVariantAttributes = NewError(fiber.StatusBadRequest, CodeInvalidVariantAttributes, "Variant attributes must set one allowed value for every product option")
+	ErrDuplicateVariant         = NewError(fiber.StatusConflict, CodeDuplicateVariant, "A variant with the same attributes already exists")
+	ErrVariantNotFound          = NewError(fiber.StatusNotFound, CodeVariantNotFound, "Variant not found")
+	ErrVariantCreate            = NewError(fiber.StatusInternalServerError, CodeVariantCreateFailed, "Could not create variant")
+	ErrVariantList              = NewError(fiber.StatusInternalServerError, CodeVariantListFailed, "Could not fetch variants")
+	ErrVariantUpdate            = NewError(fiber.StatusInternalServerError, CodeVariantUpdateFailed, "Could not update variant")
+	ErrVariantDelete            = NewError(fiber.StatusInternalServerError, CodeVariantDeleteFailed, "Could not delete variant")
+
+	ErrImageRequired     = NewError(fiber.StatusBadRequest, CodeImageRequired, "An image file is required in the image form field")
+	ErrImageTooLarge     = NewError(fiber.StatusRequestEntityTooLarge, CodeImageTooLarge, "Image file or dimensions are too large")
+	ErrUnsupportedImage  = NewError(fiber.StatusUnsupportedMediaType, CodeUnsupportedImage, "Only JPEG and PNG images are supported")
+	ErrInvalidImage      = NewError(fiber.StatusBadRequest, CodeInvalidImage, "Image file is corrupt or could not be read")
+	ErrInvalidImageOrder = NewError(fiber.StatusBadRequest, CodeInvalidImageOrder, "Image order must list every image of the product exactly once")
+	ErrImageNotFound     = NewError(fiber.StatusNotFound, CodeImageNotFound, "Image not found")
+	ErrImageUpload       = NewError(fiber.StatusInternalServerError, CodeImageUploadFailed, "Could not upload image")
+	ErrImageList         = NewError(fiber.StatusInternalServerError, CodeImageListFailed, "Could not fetch images")
+	ErrImageReorder      = NewError(fiber.StatusInternalServerError, CodeImageReorderFailed, "Could not reorder images")
+	ErrImageDelete       = NewError(fiber.StatusInternalServerError, CodeImageDeleteFailed, "Could not delete image")
+
+	ErrUnsupportedImportFormat = NewError(fiber.StatusUnsupportedMediaType, CodeUnsupportedImportFormat, "Import file must be CSV or NDJSON")
+	ErrInvalidImportFile       = NewError(fiber.StatusBadRequest, CodeInvalidImportFile, "Import file could not be read")
+	ErrInvalidExportFormat     = NewError(fiber.StatusBadRequest, CodeInvalidExportFormat, "Export format must be csv or ndjson")
+	ErrInvalidValue            = NewError(fiber.StatusBadRequest, CodeInvalidValue, "Invalid value")
+	ErrDuplicateRow            = NewError(fiber.StatusBadRequest, CodeDuplicateRow, "SKU appears more than once in the file")
+	ErrImportFailed            = NewError(fiber.StatusInternalServerError, CodeImportFailed, "Could not import products")
+	ErrJobNotFound             = NewError(fiber.StatusNotFound, CodeJobNotFound, "Job not found")
+
+	ErrRevisionNotFound = NewError(fiber.StatusNotFound, CodeRevisionNotFound, "Product version not found")
+	ErrHistoryList      = NewError(fiber.StatusInternalServerError, CodeHistoryListFailed, "Could not fetch product history")
+	ErrProductRevert    = NewError(fiber.StatusInternalServerError, CodeProductRevertFailed, "Could not revert product")
+	ErrProductRestore   = NewError(fiber.StatusInternalServerError, CodeProductRestoreFailed, "Could not restore product")
+)
+
+// Kode untuk error bawaan Fiber (route tidak ditemukan, body terlalu besar, dll)
+var statusCodes = map[int]ErrorCode{
+	fiber.StatusBadRequest:            CodeBadRequest,
+	fiber.StatusUnauthorized:          CodeUnauthorized,
+	fiber.StatusForbidden:             CodeForbidden,
+	fiber.StatusNotFound:              CodeNotFound,
+	fiber.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	fiber.StatusRequestEntityTooLarge: CodeRequestTooLarge,
+	fiber.StatusTooManyRequests:       CodeTooManyRequests,
+	fiber.StatusServiceUnavailable:    CodeServiceUnavailable,
+}
+
+// ErrorHandler adalah ErrorHandler pusat untuk Fiber.
+// AppError dirender apa adanya, *fiber.Error dipetakan ke kode berdasarkan status,
+// dan error lain dianggap INTERNAL_ERROR supaya detail internal tidak bocor.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	// Sudah ditulis oleh middleware (lihat httperr.Respond)
+	if httperr.Responded(c) {
+		return nil
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return ResponseError(c, appErr.Status, appErr.Code, appErr.Message, appErr.Data)
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code, ok := statusCodes[fiberErr.Code]
+		if !ok {
+			if fiberErr.Code >= fiber.StatusInternalServerError {
+				code = CodeInternal
+			} else {
+				code = CodeBadRequest
+			}
+		}
+		return ResponseError(c, fiberErr.Code, code, fiberErr.Message, nil)
+	}
+
+	return ResponseError(c, ErrInternal.Status, ErrInternal.Code, ErrInternal.Message, nil)
+}
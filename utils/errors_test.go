@@ -0,0 +1,131 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"learn_project/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func newApp() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: utils.ErrorHandler})
+	app.Get("/filter", func(c *fiber.Ctx) error {
+		return utils.ErrInvalidFilter.WithData(fiber.Map{"param": "status", "reason": "unknown status"})
+	})
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return errors.New("db password=rahasia")
+	})
+	return app
+}
+
+// call mengirim request lalu mengembalikan response beserta body JSON-nya
+func call(t *testing.T, app *fiber.App, method, path, accept string) (string, int, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Header.Get(fiber.HeaderContentType), resp.StatusCode, body
+}
+
+func TestErrorNegotiation(t *testing.T) {
+	app := newApp()
+	for _, tc := range []struct {
+		accept, contentType string
+		problem             bool
+	}{
+		{"", fiber.MIMEApplicationJSON, false},
+		{"application/json", fiber.MIMEApplicationJSON, false},
+		{"*/*", fiber.MIMEApplicationJSON, false},
+		{"application/problem+json", utils.MIMEProblemJSON, true},
+		{"application/json;q=0.5, application/problem+json", utils.MIMEProblemJSON, true},
+	} {
+		contentType, status, body := call(t, app, fiber.MethodGet, "/filter?x=1", tc.accept)
+		if status != fiber.StatusBadRequest || !strings.HasPrefix(contentType, tc.contentType) {
+			t.Errorf("Accept %q: status %d, Content-Type %q", tc.accept, status, contentType)
+			continue
+		}
+		if _, ok := body["detail"]; ok != tc.problem {
+			t.Errorf("Accept %q: body = %v", tc.accept, body)
+		}
+		if body["code"] != string(utils.ErrInvalidFilter.Code) {
+			t.Errorf("Accept %q: code = %v", tc.accept, body["code"])
+		}
+	}
+}
+
+// Bentuk AppError dengan data detail validasi di kedua format
+func TestErrorShape(t *testing.T) {
+	app := newApp()
+	data := map[string]any{"param": "status", "reason": "unknown status"}
+
+	_, _, body := call(t, app, fiber.MethodGet, "/filter", "")
+	want := map[string]any{
+		"status":  float64(fiber.StatusBadRequest),
+		"code":    string(utils.ErrInvalidFilter.Code),
+		"message": utils.ErrInvalidFilter.Message,
+		"data":    data,
+	}
+	if !sameJSON(body, want) {
+		t.Errorf("json = %v, want %v", body, want)
+	}
+
+	_, _, body = call(t, app, fiber.MethodGet, "/filter?x=1", utils.MIMEProblemJSON)
+	want = map[string]any{
+		"type":     "about:blank",
+		"title":    "Bad Request",
+		"status":   float64(fiber.StatusBadRequest),
+		"detail":   utils.ErrInvalidFilter.Message,
+		"instance": "/filter?x=1",
+		"code":     string(utils.ErrInvalidFilter.Code),
+		"data":     data,
+	}
+	if !sameJSON(body, want) {
+		t.Errorf("problem = %v, want %v", body, want)
+	}
+}
+
+// Error bawaan Fiber dan error lain dipetakan ke katalog kode error
+func TestErrorMapping(t *testing.T) {
+	app := newApp()
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		code         utils.ErrorCode
+	}{
+		{fiber.MethodGet, "/tidak-ada", fiber.StatusNotFound, utils.CodeNotFound},
+		{fiber.MethodPost, "/filter", fiber.StatusMethodNotAllowed, utils.CodeMethodNotAllowed},
+		{fiber.MethodGet, "/boom", fiber.StatusInternalServerError, utils.CodeInternal},
+	} {
+		_, status, body := call(t, app, tc.method, tc.path, utils.MIMEProblemJSON)
+		if status != tc.status || body["status"] != float64(tc.status) || body["code"] != string(tc.code) {
+			t.Errorf("%s %s: status %d, body %v", tc.method, tc.path, status, body)
+		}
+	}
+
+	// Pesan error internal tidak pernah dikirim ke client
+	_, _, body := call(t, app, fiber.MethodGet, "/boom", "")
+	if body["message"] != utils.ErrInternal.Message {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func sameJSON(got, want map[string]any) bool {
+	a, _ := json.Marshal(got)
+	b, _ := json.Marshal(want)
+	return string(a) == string(b)
+}
@@ -1,14 +1,29 @@
 package utils
 
 import (
-	"os"
+	"context"
 	"time"
 
+	"learn_project/config"
+	"learn_project/tracing"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
-var jwtRefreshKey = []byte(os.Getenv("JWT_SECRET_REFRESH"))
+// JWTManager menyimpan key untuk access token dan refresh token.
+// Dibuat dari config supaya key tidak lagi dibaca saat init (sebelum .env dimuat).
+type JWTManager struct {
+	key        []byte
+	refreshKey []byte
+}
+
+// NewJWTManager membuat JWTManager dari konfigurasi JWT
+func NewJWTManager(cfg config.JWTConfig) *JWTManager {
+	return &JWTManager{
+		key:        []byte(cfg.Secret),
+		refreshKey: []byte(cfg.RefreshSecret),
+	}
+}
 
 // Claims struct untuk token JWT
 type Claims struct {
@@ -16,37 +31,47 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// Generate JWT Access Token (Berlaku 1 Jam)
-func GenerateToken(email string) (string, string, error) {
+// Generate JWT Access Token (Berlaku 1 Hari)
+// userID disimpan sebagai subject (sub) supaya bisa dicatat di log tanpa query ke database
+func (m *JWTManager) GenerateToken(ctx context.Context, userID, email string) (string, string, error) {
+	_, span := tracing.Start(ctx, "jwt.sign_access_token")
+	defer span.End()
+
 	expirationTime := time.Now().Add(24 * time.Hour) // Berlaku 1 hari
 
 	claims := &Claims{
 		Email: email,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(jwtKey)
+	signedToken, err := token.SignedString(m.key)
 	if err != nil {
 		return "", "", err
 	}
 
 	return signedToken, "1 day", nil
 }
+
 // Generate Refresh Token (Berlaku 7 Hari)
-func GenerateRefreshToken(email string) (string, error) {
+func (m *JWTManager) GenerateRefreshToken(ctx context.Context, userID, email string) (string, error) {
+	_, span := tracing.Start(ctx, "jwt.sign_refresh_token")
+	defer span.End()
+
 	expirationTime := time.Now().Add(time.Hour * 24 * 7) // Berlaku 7 hari
 	claims := &Claims{
 		Email: email,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(jwtRefreshKey)
+	signedToken, err := token.SignedString(m.refreshKey)
 	if err != nil {
 		return "", err
 	}
@@ -55,10 +80,10 @@ func GenerateRefreshToken(email string) (string, error) {
 }
 
 // Validasi Token
-func ValidateToken(tokenString string) (*Claims, error) {
+func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
+		return m.key, nil
 	})
 
 	if err != nil || !token.Valid {
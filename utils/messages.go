@@ -0,0 +1,57 @@
+package utils
+
+// SuccessCode adalah kode untuk response sukses, dipakai sebagai key katalog pesan di package i18n
+type SuccessCode string
+
+const (
+	MsgUserRegistered SuccessCode = "USER_REGISTERED"
+	MsgLoginSuccess   SuccessCode = "LOGIN_SUCCESS"
+	MsgUserRetrieved  SuccessCode = "USER_RETRIEVED"
+
+	MsgBankAdded      SuccessCode = "BANK_ADDED"
+	MsgBanksRetrieved SuccessCode = "BANKS_RETRIEVED"
+	MsgBankRetrieved  SuccessCode = "BANK_RETRIEVED"
+	MsgBankUpdated    SuccessCode = "BANK_UPDATED"
+	MsgBankDeleted    SuccessCode = "BANK_DELETED"
+	MsgMoneyAdded     SuccessCode = "MONEY_ADDED"
+
+	MsgProductCreated    SuccessCode = "PRODUCT_CREATED"
+	MsgProductsRetrieved SuccessCode = "PRODUCTS_RETRIEVED"
+	MsgProductRetrieved  SuccessCode = "PRODUCT_RETRIEVED"
+	MsgProductUpdated    SuccessCode = "PRODUCT_UPDATED"
+	MsgProductDeleted    SuccessCode = "PRODUCT_DELETED"
+
+	MsgSellerRetrieved SuccessCode = "SELLER_RETRIEVED"
+	MsgSellerSaved     SuccessCode = "SELLER_SAVED"
+
+	MsgStockUpdated       SuccessCode = "STOCK_UPDATED"
+	MsgMovementsRetrieved SuccessCode = "STOCK_MOVEMENTS_RETRIEVED"
+
+	MsgCategoryCreated       SuccessCode = "CATEGORY_CREATED"
+	MsgCategoriesRetrieved   SuccessCode = "CATEGORIES_RETRIEVED"
+	MsgCategoryTreeRetrieved SuccessCode = "CATEGORY_TREE_RETRIEVED"
+	MsgCategoryRetrieved     SuccessCode = "CATEGORY_RETRIEVED"
+	MsgCategoryUpdated       SuccessCode = "CATEGORY_UPDATED"
+	MsgCategoryDeleted       SuccessCode = "CATEGORY_DELETED"
+
+	MsgVariantCreated    SuccessCode = "VARIANT_CREATED"
+	MsgVariantsRetrieved SuccessCode = "VARIANTS_RETRIEVED"
+	MsgVariantRetrieved  SuccessCode = "VARIANT_RETRIEVED"
+	MsgVariantUpdated    SuccessCode = "VARIANT_UPDATED"
+	MsgVariantDeleted    SuccessCode = "VARIANT_DELETED"
+
+	MsgImageUploaded   SuccessCode = "IMAGE_UPLOADED"
+	MsgImagesRetrieved SuccessCode = "IMAGES_RETRIEVED"
+	MsgImagesReordered SuccessCode = "IMAGES_REORDERED"
+	MsgImageDeleted    SuccessCode = "IMAGE_DELETED"
+
+	MsgImportCompleted SuccessCode = "IMPORT_COMPLETED"
+	MsgImportValidated SuccessCode = "IMPORT_VALIDATED"
+	MsgImportStarted   SuccessCode = "IMPORT_STARTED"
+	MsgJobRetrieved    SuccessCode = "JOB_RETRIEVED"
+
+	MsgHistoryRetrieved SuccessCode = "HISTORY_RETRIEVED"
+	MsgProductReverted  SuccessCode = "PRODUCT_REVERTED"
+	MsgTrashRetrieved   SuccessCode = "TRASH_RETRIEVED"
+	MsgProductRestored  SuccessCode = "PRODUCT_RESTORED"
+)
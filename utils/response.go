@@ -1,28 +1,70 @@
 package utils
 
 import (
+	"learn_project/i18n"
+	"net/http"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+// MIMEProblemJSON adalah content type RFC 7807 (Problem Details for HTTP APIs)
+const MIMEProblemJSON = "application/problem+json"
+
 // ResponseError function
-func ResponseError(c *fiber.Ctx, status int, message string, data interface{}) error {
-	return c.Status(status).JSON(fiber.Map{"status": status, "message": message, "data": data})
+// Client yang mengirim "Accept: application/problem+json" mendapat body RFC 7807,
+// selain itu tetap format JSON lama ditambah field "code".
+// Message diterjemahkan berdasarkan kode sesuai Accept-Language.
+func ResponseError(c *fiber.Ctx, status int, code ErrorCode, message string, data interface{}) error {
+	message = i18n.Message(c, string(code), message)
+
+	if c.Accepts(fiber.MIMEApplicationJSON, MIMEProblemJSON) == MIMEProblemJSON {
+		problem := fiber.Map{
+			"type":     "about:blank",
+			"title":    http.StatusText(status),
+			"status":   status,
+			"detail":   message,
+			"instance": c.OriginalURL(),
+			"code":     code,
+		}
+		if data != nil {
+			problem["data"] = data
+		}
+		return c.Status(status).JSON(problem, MIMEProblemJSON)
+	}
+
+	return c.Status(status).JSON(fiber.Map{"status": status, "code": code, "message": message, "data": data})
 }
 
 // ResponseSuccessOneData function
-func ResponseSuccessOneData(c *fiber.Ctx, message string, data interface{}) error {
-	
-	return c.Status(200).JSON(fiber.Map{"status": 200, "message": message, "data": data})
+func ResponseSuccessOneData(c *fiber.Ctx, code SuccessCode, data interface{}) error {
+	message := i18n.Message(c, string(code), string(code))
+	return c.Status(200).JSON(fiber.Map{"status": 200, "code": code, "message": message, "data": data})
+}
+
+// ResponseAccepted dipakai kalau request diproses di background (202 Accepted)
+func ResponseAccepted(c *fiber.Ctx, code SuccessCode, data interface{}) error {
+	message := i18n.Message(c, string(code), string(code))
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"status": fiber.StatusAccepted, "code": code, "message": message, "data": data})
 }
 
 // ResponseSuccessManyData function
-func ResponseSuccessManyData(c *fiber.Ctx, message string, data interface{}, page int, limit int, count int) error {
-	return c.Status(200).JSON(fiber.Map{
-		"status":  200,
-		"message": message,
-		"data":    data,
-		"page":    page,
-		"limit":   limit,
-		"count":   count,
-	})
-}
\ No newline at end of file
+// page 0 berarti request memakai cursor, sehingga field page tidak dikirim.
+// next_cursor null kalau tidak ada halaman berikutnya.
+func ResponseSuccessManyData(c *fiber.Ctx, code SuccessCode, data interface{}, page int, limit int, count int, nextCursor string) error {
+	body := fiber.Map{
+		"status":      200,
+		"code":        code,
+		"message":     i18n.Message(c, string(code), string(code)),
+		"data":        data,
+		"limit":       limit,
+		"count":       count,
+		"next_cursor": nil,
+	}
+	if page > 0 {
+		body["page"] = page
+	}
+	if nextCursor != "" {
+		body["next_cursor"] = nextCursor
+	}
+	return c.Status(200).JSON(body)
+}